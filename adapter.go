@@ -0,0 +1,223 @@
+package mux
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WrapHandler adapts an http.Handler into a Handler
+func WrapHandler(h http.Handler) Handler {
+	return func(c *Context) error {
+		h.ServeHTTP(c.w, c.r)
+		return nil
+	}
+}
+
+// WrapFunc adapts an http.HandlerFunc into a Handler
+func WrapFunc(f http.HandlerFunc) Handler {
+	return WrapHandler(f)
+}
+
+// stdCall carries the Context through a std middleware chain
+type stdCall struct {
+	c   *Context
+	err error
+
+	// writer of the Context when the std middleware replaces it
+	rw ResponseWriter
+}
+
+type stdCallKey struct{}
+
+// withoutStdCall hides the stdCall of a chain from the next handler
+type withoutStdCall struct {
+	context.Context
+}
+
+func (ctx withoutStdCall) Value(key any) any {
+	if key == (stdCallKey{}) {
+		return nil
+	}
+	return ctx.Context.Value(key)
+}
+
+// contexts used by ToStd outside a Router
+var stdContexts pool[Context]
+
+// FromStd adapts a func(http.Handler) http.Handler middleware into a
+// Middleware. The pooled Context is passed through the std middleware.
+// Requests and writers it replaces are visible to the next handler, and
+// the error returned by the next handler is returned to the caller. If
+// the middleware replaces the request context without deriving from it,
+// the next handler is not run and ErrDetachedContext is returned.
+func FromStd(std func(http.Handler) http.Handler) Middleware {
+	return func(next Handler) Handler {
+		h := std(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			call, ok := req.Context().Value(stdCallKey{}).(*stdCall)
+			if !ok {
+				// unwind the std middleware to the call, which cannot be
+				// found otherwise
+				panic(ErrDetachedContext)
+			}
+			c := call.c
+
+			w0, r, query := c.w, c.r, c.query
+			defer func() {
+				c.w, c.r, c.query = w0, r, query
+			}()
+
+			if w != http.ResponseWriter(w0) {
+				call.rw = ResponseWriter{ResponseWriter: w}
+				c.w = &call.rw
+			}
+			c.r, c.query = req.WithContext(withoutStdCall{req.Context()}), nil
+
+			call.err = next(c)
+		}))
+
+		return func(c *Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					if p != ErrDetachedContext {
+						panic(p)
+					}
+					err = ErrDetachedContext
+				}
+			}()
+
+			call := &stdCall{c: c}
+			h.ServeHTTP(c.w, c.r.WithContext(context.WithValue(c.r.Context(), stdCallKey{}, call)))
+			return call.err
+		}
+	}
+}
+
+// serveStd serves a request with h and a Context from stdContexts.
+// Errors go to onErr when given, otherwise a 500 response is written if
+// nothing was written yet.
+func serveStd(h Handler, w http.ResponseWriter, req *http.Request, onErr []ErrorHandler) {
+	c := stdContexts.get()
+	c.attach(w, req)
+
+	defer func() {
+		c.detach()
+		stdContexts.put(c)
+	}()
+
+	err := h(c)
+	if err == nil {
+		return
+	}
+	if len(onErr) > 0 {
+		onErr[0](c, err)
+		return
+	}
+	if c.w.Status() == 0 && c.w.Size() == 0 {
+		_ = c.InternalServerError(M{"error": "internal server error", "message": err.Error()})
+	}
+}
+
+// ToStd adapts a Middleware into a func(http.Handler) http.Handler
+// middleware. Errors returned by the middleware go to onErr when given,
+// otherwise a 500 response is written if nothing was written yet.
+func ToStd(mw Middleware, onErr ...ErrorHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		h := mw(WrapHandler(next))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			serveStd(h, w, req, onErr)
+		})
+	}
+}
+
+// mountPattern returns the subtree pattern for a mount prefix
+func mountPattern(prefix string) string {
+	return strings.TrimSuffix(prefix, "/") + "/"
+}
+
+// mountHandler strips the segments matched by prefix before calling h.
+// Path values of the prefix wildcards stay available to h, and to
+// Context.Param of a mounted Router after its own match replaced them.
+func mountHandler(prefix string, h http.Handler) Handler {
+	prefix, _ = parseConstraints(strings.TrimSuffix(prefix, "/"))
+	depth := strings.Count(prefix, "/")
+
+	var names []string
+	for _, seg := range strings.Split(prefix, "/") {
+		if isWildcard(seg) {
+			names = append(names, strings.TrimSuffix(seg[1:len(seg)-1], "..."))
+		}
+	}
+
+	return func(c *Context) error {
+		h.ServeHTTP(c.w, mountRequest(c.r, depth, names))
+		return nil
+	}
+}
+
+// mountRequest returns a copy of req with the first depth path segments
+// removed
+func mountRequest(req *http.Request, depth int, names []string) *http.Request {
+	u := new(url.URL)
+	*u = *req.URL
+	u.Path = trimSegments(u.Path, depth)
+	if u.RawPath != "" {
+		u.RawPath = trimSegments(u.RawPath, depth)
+	}
+
+	if len(names) == 0 {
+		r2 := req.WithContext(req.Context())
+		r2.URL = u
+		return r2
+	}
+
+	// a nested ServeMux replaces the match of the prefix, so its values
+	// are also kept in the context. The clone has its own copy of the
+	// match for the path values a nested matcher sets.
+	parent, _ := req.Context().Value(mountKey{}).(map[string]string)
+	values := make(map[string]string, len(parent)+len(names))
+	for name, v := range parent {
+		values[name] = v
+	}
+	for _, name := range names {
+		values[name] = req.PathValue(name)
+	}
+	r2 := req.Clone(context.WithValue(req.Context(), mountKey{}, values))
+	r2.URL = u
+	return r2
+}
+
+// mountKey is the context key of the path values of mount prefixes
+type mountKey struct{}
+
+// mountValue returns the path value of a mount prefix wildcard of req
+func mountValue(req *http.Request, name string) string {
+	values, _ := req.Context().Value(mountKey{}).(map[string]string)
+	return values[name]
+}
+
+// trimSegments removes the first n segments from the path p
+func trimSegments(p string, n int) string {
+	for range n {
+		if p == "" {
+			return "/"
+		}
+		i := strings.IndexByte(p[1:], '/')
+		if i < 0 {
+			return "/"
+		}
+		p = p[i+1:]
+	}
+	return p
+}
+
+// handlerName returns a printable name for an http.Handler
+func handlerName(h http.Handler) string {
+	if f, ok := h.(http.HandlerFunc); ok {
+		return funcName(f)
+	}
+	return fmt.Sprintf("%T", h)
+}
@@ -0,0 +1,379 @@
+package mux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Handler Adapters
+// -----------------------------------------------------------------------------
+
+func TestWrapHandler(t *testing.T) {
+	r := New()
+	r.GET("/std", WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(req.URL.Path))
+	})))
+
+	req := httptest.NewRequest("GET", "/std", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 418 {
+		t.Errorf("expected 418, got %d", rec.Code)
+	}
+	if rec.Body.String() != "/std" {
+		t.Errorf("expected /std, got %s", rec.Body.String())
+	}
+}
+
+func TestWrapFuncPathValues(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", WrapFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.PathValue("id")))
+	}))
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "42" {
+		t.Errorf("expected 42, got %s", rec.Body.String())
+	}
+}
+
+func TestWrapHandlerMiddleware(t *testing.T) {
+	r := New()
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			c.SetHeader("X-Middleware", "yes")
+			return next(c)
+		}
+	})
+	r.GET("/std", WrapHandler(http.NotFoundHandler()))
+
+	req := httptest.NewRequest("GET", "/std", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Middleware") != "yes" {
+		t.Error("expected middleware to run for wrapped handler")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Std Middleware Adapters
+// -----------------------------------------------------------------------------
+
+type ctxKey string
+
+func TestFromStd(t *testing.T) {
+	r := New()
+
+	var order []string
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			order = append(order, "std")
+			w.Header().Set("X-Std", "yes")
+			next.ServeHTTP(w, req)
+		})
+	}))
+	r.GET("/test", func(c *Context) error {
+		order = append(order, "handler")
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if len(order) != 2 || order[0] != "std" || order[1] != "handler" {
+		t.Errorf("expected [std handler], got %v", order)
+	}
+	if rec.Header().Get("X-Std") != "yes" {
+		t.Error("expected header set by std middleware")
+	}
+}
+
+func TestFromStdPreservesContext(t *testing.T) {
+	r := New()
+
+	var outer, inner *Context
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			outer = c
+			return next(c)
+		}
+	})
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return next
+	}))
+	r.GET("/test", func(c *Context) error {
+		inner = c
+		c.Set("seen", true)
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if outer == nil || outer != inner {
+		t.Error("expected the same Context inside and outside std middleware")
+	}
+}
+
+func TestFromStdReturnsError(t *testing.T) {
+	r := New()
+
+	var capturedErr error
+	r.OnErr(func(c *Context, err error) {
+		capturedErr = err
+		_ = c.InternalServerError(nil)
+	})
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return next
+	}))
+	r.GET("/test", func(c *Context) error {
+		return errors.New("handler failed")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if capturedErr == nil || capturedErr.Error() != "handler failed" {
+		t.Errorf("expected handler error, got %v", capturedErr)
+	}
+}
+
+func TestFromStdRequestReplacement(t *testing.T) {
+	r := New()
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), ctxKey("user"), "alice")
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}))
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"user": c.Context().Value(ctxKey("user")), "id": c.Param("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/users/7", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["user"] != "alice" {
+		t.Errorf("expected user=alice, got %v", body["user"])
+	}
+	if body["id"] != "7" {
+		t.Errorf("expected id=7, got %v", body["id"])
+	}
+}
+
+func TestFromStdWriterReplacement(t *testing.T) {
+	r := New()
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(upperWriter{w}, req)
+		})
+	}))
+	r.GET("/test", func(c *Context) error {
+		return c.String(200, "hello")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "HELLO" {
+		t.Errorf("expected HELLO, got %s", rec.Body.String())
+	}
+}
+
+func TestFromStdDetachedContext(t *testing.T) {
+	r := New()
+	r.OnErr(func(c *Context, err error) {
+		if errors.Is(err, ErrDetachedContext) {
+			_ = c.String(502, "detached")
+		}
+	})
+	r.Use(FromStd(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(context.Background(), ctxKey("user"), "alice")
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}))
+	r.GET("/", func(c *Context) error {
+		t.Error("expected the handler not to run with a detached context")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 502 || rec.Body.String() != "detached" {
+		t.Errorf("expected ErrDetachedContext in the router's error handler, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFromStdPanics(t *testing.T) {
+	var got error
+	r := New()
+	r.OnErr(func(c *Context, err error) { got = err })
+	r.Use(FromStd(func(next http.Handler) http.Handler { return next }))
+	r.GET("/", func(c *Context) error { panic("boom") })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got == nil || got.Error() != "panic: boom" {
+		t.Errorf("expected the handler panic to pass through, got %v", got)
+	}
+}
+
+func TestFromStdHidesCall(t *testing.T) {
+	r := New()
+	r.Use(FromStd(func(next http.Handler) http.Handler { return next }))
+	r.GET("/", func(c *Context) error {
+		if c.Context().Value(stdCallKey{}) != nil {
+			t.Error("expected the std call hidden from the handler")
+		}
+		return c.NoContent()
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+type upperWriter struct {
+	http.ResponseWriter
+}
+
+func (w upperWriter) Write(b []byte) (int, error) {
+	return w.ResponseWriter.Write(bytes.ToUpper(b))
+}
+
+func TestToStd(t *testing.T) {
+	mw := func(next Handler) Handler {
+		return func(c *Context) error {
+			c.SetHeader("X-Mux", "yes")
+			return next(c)
+		}
+	}
+
+	h := ToStd(mw)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != 202 {
+		t.Errorf("expected 202, got %d", rec.Code)
+	}
+	if rec.Header().Get("X-Mux") != "yes" {
+		t.Error("expected header set by mux middleware")
+	}
+}
+
+func TestToStdError(t *testing.T) {
+	mw := func(next Handler) Handler {
+		return func(c *Context) error {
+			return errors.New("denied")
+		}
+	}
+
+	h := ToStd(mw)(http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != 500 {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestToStdErrorHandler(t *testing.T) {
+	mw := func(next Handler) Handler {
+		return func(c *Context) error {
+			return errors.New("denied")
+		}
+	}
+
+	h := ToStd(mw, func(c *Context, err error) {
+		_ = c.Forbidden(M{"error": err.Error()})
+	})(http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != 403 {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Internal
+// -----------------------------------------------------------------------------
+
+func TestTrimSegments(t *testing.T) {
+	tests := []struct {
+		path string
+		n    int
+		want string
+	}{
+		{"/static/css/app.css", 1, "/css/app.css"},
+		{"/static/", 1, "/"},
+		{"/static", 1, "/"},
+		{"/tenants/42/files/a", 2, "/files/a"},
+		{"/a/b", 0, "/a/b"},
+		{"", 1, "/"},
+	}
+
+	for _, tt := range tests {
+		if got := trimSegments(tt.path, tt.n); got != tt.want {
+			t.Errorf("trimSegments(%q, %d) = %q, want %q", tt.path, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMountRequestCopiesFields(t *testing.T) {
+	req := httptest.NewRequest("POST", "/tenants/42/files/a", strings.NewReader("x"))
+	req.Pattern = "/tenants/{id}/"
+	req.SetPathValue("id", "42")
+	req.Form = url.Values{"a": {"1"}}
+
+	r2 := mountRequest(req, 2, []string{"id"})
+	if r2.URL.Path != "/files/a" || req.URL.Path != "/tenants/42/files/a" {
+		t.Errorf("expected trimmed copy, got %s and %s", r2.URL.Path, req.URL.Path)
+	}
+	if r2.PathValue("id") != "42" || mountValue(r2, "id") != "42" {
+		t.Error("expected path values of the request")
+	}
+	r2.SetPathValue("id", "7")
+	if req.PathValue("id") != "42" {
+		t.Errorf("expected the parent match untouched, got %q", req.PathValue("id"))
+	}
+
+	v, v2 := reflect.ValueOf(req).Elem(), reflect.ValueOf(r2).Elem()
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() || f.Name == "URL" || f.Type.Kind() == reflect.Func {
+			continue
+		}
+		if !reflect.DeepEqual(v.Field(i).Interface(), v2.Field(i).Interface()) {
+			t.Errorf("expected %s copied", f.Name)
+		}
+	}
+}
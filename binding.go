@@ -1,11 +1,14 @@
 package mux
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -78,3 +81,120 @@ func decodeJSON(c *Context, v any) error {
 
 	return err
 }
+
+// bindParams sets the fields of the struct v tagged path, query or header
+// from the request. Values that do not convert are added to errs.
+func bindParams(c *Context, v reflect.Value, errs *[]Violation) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f, fv := t.Field(i), v.Field(i)
+		if f.Anonymous && f.Tag == "" {
+			if fv.Kind() == reflect.Pointer && fv.IsNil() && fv.CanSet() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			bindParams(c, fv, errs)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		for _, in := range []string{"path", "query", "header"} {
+			name, _, _ := strings.Cut(f.Tag.Get(in), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			var raw []string
+			switch in {
+			case "path":
+				if s := c.Param(name); s != "" {
+					raw = []string{s}
+				}
+			case "query":
+				raw = c.queries()[name]
+			case "header":
+				raw = c.r.Header.Values(name)
+			}
+			if len(raw) == 0 {
+				continue
+			}
+			if err := setParam(fv, raw); err != nil {
+				*errs = append(*errs, Violation{In: in, Name: name, Detail: err.Error()})
+			}
+		}
+	}
+}
+
+// setParam converts raw parameter values to the type of v. Slices take
+// repeated values or a comma-separated value.
+func setParam(v reflect.Value, raw []string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setParam(v.Elem(), raw)
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		if len(raw) == 1 {
+			raw = strings.Split(raw[0], ",")
+		}
+		s := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+		for i, r := range raw {
+			if err := setScalar(s.Index(i), r); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setScalar(v, raw[0])
+}
+
+// setScalar converts a raw value to the type of v
+func setScalar(v reflect.Value, s string) error {
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := tu.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q", s)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected boolean, got %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected integer, got %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected unsigned integer, got %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected number, got %q", s)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported parameter type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,636 @@
+package mux
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ClientConfig configures GenerateClient
+type ClientConfig struct {
+	// Package name of the generated file. Default: client
+	Package string
+
+	// APIVersion limits Router.GenerateClient to unversioned routes and
+	// the routes of this API version
+	APIVersion string
+}
+
+// GenerateClient generates the routes of r as a Go client, like
+// GenerateClient does for the OpenAPI document of r
+func (r *Router) GenerateClient(config ...ClientConfig) ([]byte, error) {
+	var cfg ClientConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return GenerateClient(r.OpenAPI(OpenAPIConfig{APIVersion: cfg.APIVersion}), cfg)
+}
+
+// GenerateClient generates the source of a Go client package for doc.
+// The client has a method per operation, named after the operation id,
+// which takes the path parameters as arguments, query and header
+// parameters in a Params struct and the JSON body, and returns the JSON
+// result of the first success response. Component schemas become Go
+// types; a schema named like a type of the client, such as Problem, gets
+// a Schema suffix. Error responses are returned as *Error, decoded from HTTPError
+// JSON or Problem bodies.
+func GenerateClient(doc *OpenAPI, config ...ClientConfig) ([]byte, error) {
+	var cfg ClientConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Package == "" {
+		cfg.Package = "client"
+	}
+
+	g := &clientGen{doc: doc}
+	if doc.Components != nil {
+		g.schemas = doc.Components.Schemas
+	}
+	g.nameTypes()
+
+	var body bytes.Buffer
+	g.buf = &body
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		for _, method := range docMethods {
+			if op := item.Operation(method); op != nil {
+				if err := g.operation(method, path, item, op); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(g.schemas)) {
+		s := g.schemas[name]
+		g.printf("\n")
+		g.comment(g.names[name], s.Description)
+		g.printf("type %s %s\n", g.names[name], g.goType(s))
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by muxgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s is a client for the %s API.\n", cfg.Package, doc.Info.Title)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", cfg.Package)
+	imports := slices.Clone(clientImports)
+	if g.time {
+		imports = append(imports, "time")
+	}
+	slices.Sort(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	fmt.Fprintf(&out, ")\n\n%s", clientRuntime)
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("mux: format generated client: %w", err)
+	}
+	return src, nil
+}
+
+// clientGen writes the operations and types of a client
+type clientGen struct {
+	doc     *OpenAPI
+	schemas map[string]*Schema
+	buf     *bytes.Buffer
+	time    bool
+
+	// Go type names of the component schemas
+	names map[string]string
+}
+
+// clientImports are the packages of the generated client besides time
+var clientImports = []string{"bytes", "context", "encoding", "encoding/json", "fmt", "io", "mime", "net/http", "net/url", "reflect", "strings"}
+
+// clientIdents are the names generated methods refer to besides their
+// arguments: locals, runtime helpers and imported packages
+var clientIdents = func() map[string]bool {
+	idents := make(map[string]bool)
+	for _, name := range []string{"ctx", "params", "body", "c", "out", "path", "query", "header", "err", "pathValue", "addValues", "decodeError", "time"} {
+		idents[name] = true
+	}
+	for _, imp := range clientImports {
+		idents[imp[strings.LastIndexByte(imp, '/')+1:]] = true
+	}
+	return idents
+}()
+
+// clientTypes are the names declared by clientRuntime
+var clientTypes = []string{"Client", "NewClient", "Error", "Problem", "Violation"}
+
+// nameTypes picks a Go type name for each component schema that does not
+// clash with the client runtime, the Params types or other schemas. A
+// clashing name gets a Schema suffix, like ProblemSchema.
+func (g *clientGen) nameTypes() {
+	taken := make(map[string]bool)
+	for _, name := range clientTypes {
+		taken[name] = true
+	}
+	for _, item := range g.doc.Paths {
+		for _, method := range docMethods {
+			if op := item.Operation(method); op != nil && op.OperationID != "" {
+				taken[goName(op.OperationID)+"Params"] = true
+			}
+		}
+	}
+
+	g.names = make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(g.schemas)) {
+		typ := goName(name)
+		if taken[typ] {
+			typ += "Schema"
+		}
+		for i := 2; taken[typ]; i++ {
+			typ = goName(name) + "Schema" + strconv.Itoa(i)
+		}
+		taken[typ] = true
+		g.names[name] = typ
+	}
+}
+
+func (g *clientGen) printf(format string, args ...any) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+// comment writes a doc comment starting with name when text is set
+func (g *clientGen) comment(name, text string) {
+	if text == "" {
+		return
+	}
+	for line := range strings.SplitSeq(name+" "+lowerFirst(text), "\n") {
+		g.printf("// %s\n", line)
+	}
+}
+
+// operation writes the Params type and client method of an operation
+func (g *clientGen) operation(method, path string, item *PathItem, op *Operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("mux: operation %s %s has no operation id", method, path)
+	}
+	name := goName(op.OperationID)
+
+	var pathParams, otherParams []*Parameter
+	for _, p := range slices.Concat(item.Parameters, op.Parameters) {
+		p = g.parameter(p)
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query", "header":
+			otherParams = append(otherParams, p)
+		}
+	}
+
+	// signature
+	args := []string{"ctx context.Context"}
+	used := maps.Clone(clientIdents)
+	argNames := make(map[string]string)
+	for _, p := range pathParams {
+		arg := lowerFirst(goName(p.Name))
+		for used[arg] || token.IsKeyword(arg) || types.Universe.Lookup(arg) != nil {
+			arg += "Param"
+		}
+		used[arg] = true
+		argNames[p.Name] = arg
+		args = append(args, arg+" "+g.goType(p.Schema))
+	}
+	if len(otherParams) > 0 {
+		args = append(args, "params *"+name+"Params")
+	}
+	var bodySchema *Schema
+	if op.RequestBody != nil {
+		rb := op.RequestBody
+		if rb.Ref != "" {
+			rb = resolveRef(rb, rb.Ref, "requestBodies", g.doc.Components.RequestBodies)
+		}
+		if mt := rb.Content[MIMEApplicationJSON]; mt != nil && mt.Schema != nil {
+			bodySchema = mt.Schema
+			args = append(args, "body "+g.goType(bodySchema))
+		}
+	}
+	result := g.result(op)
+
+	// params type
+	if len(otherParams) > 0 {
+		g.printf("\n// %sParams are the query and header parameters of %s\n", name, name)
+		g.printf("type %sParams struct {\n", name)
+		for _, p := range otherParams {
+			if p.Description != "" {
+				g.printf("// %s\n", p.Description)
+			}
+			g.printf("%s %s\n", goName(p.Name), g.goType(p.Schema))
+		}
+		g.printf("}\n")
+	}
+
+	// method
+	g.printf("\n")
+	doc := op.Summary
+	if doc == "" {
+		doc = fmt.Sprintf("calls %s %s", method, path)
+	}
+	g.comment(name, doc)
+	if op.Deprecated {
+		g.printf("//\n// Deprecated: the operation is deprecated.\n")
+	}
+	if result != "" {
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+		g.printf("var out %s\n", result)
+	} else {
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	}
+
+	g.printf("path := %s\n", g.pathExpr(path, argNames))
+	g.printf("query, header := url.Values{}, http.Header{}\n")
+	if len(otherParams) > 0 {
+		g.printf("if params != nil {\n")
+		for _, p := range otherParams {
+			add := "query.Add"
+			if p.In == "header" {
+				add = "header.Add"
+			}
+			g.printf("addValues(%s, %q, params.%s)\n", add, p.Name, goName(p.Name))
+		}
+		g.printf("}\n")
+	}
+
+	bodyArg, outArg := "nil", "nil"
+	if bodySchema != nil {
+		bodyArg = "body"
+	}
+	if result != "" {
+		outArg = "&out"
+		g.printf("err := c.do(ctx, %q, path, query, header, %s, %s)\n", method, bodyArg, outArg)
+		g.printf("return out, err\n}\n")
+	} else {
+		g.printf("return c.do(ctx, %q, path, query, header, %s, %s)\n}\n", method, bodyArg, outArg)
+	}
+	return nil
+}
+
+// parameter resolves a parameter reference
+func (g *clientGen) parameter(p *Parameter) *Parameter {
+	if p.Ref == "" {
+		return p
+	}
+	return resolveRef(p, p.Ref, "parameters", g.doc.Components.Parameters)
+}
+
+// result returns the Go type of the JSON content of the first success
+// response, or "" when it has none
+func (g *clientGen) result(op *Operation) string {
+	statuses := slices.Sorted(maps.Keys(op.Responses))
+	for _, status := range append(statuses, "default") {
+		if status != "default" && (status == "" || status[0] != '2') {
+			continue
+		}
+		res := op.Responses[status]
+		if res == nil {
+			continue
+		}
+		if status == "204" || status == "304" {
+			return ""
+		}
+		if res.Ref != "" {
+			res = resolveRef(res, res.Ref, "responses", g.doc.Components.Responses)
+		}
+		if mt := res.Content[MIMEApplicationJSON]; mt != nil && mt.Schema != nil {
+			return g.goType(mt.Schema)
+		}
+		if status != "default" {
+			return ""
+		}
+	}
+	return ""
+}
+
+// pathExpr returns a Go expression building path with the arguments
+// substituted for its parameters
+func (g *clientGen) pathExpr(path string, args map[string]string) string {
+	var parts []string
+	for {
+		i := strings.IndexByte(path, '{')
+		end := strings.IndexByte(path, '}')
+		if i < 0 || end < i {
+			break
+		}
+		if i > 0 {
+			parts = append(parts, strconv.Quote(path[:i]))
+		}
+		parts = append(parts, "pathValue("+args[path[i+1:end]]+")")
+		path = path[end+1:]
+	}
+	if path != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(path))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// goType returns the Go type for values of the schema
+func (g *clientGen) goType(s *Schema) string {
+	if s == nil {
+		return "any"
+	}
+	s = orNull(s)
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if typ, ok := g.names[name]; ok {
+			return typ
+		}
+		return goName(name)
+	}
+
+	var typ string
+	for _, t := range s.Type {
+		if t != "null" {
+			typ = t
+			break
+		}
+	}
+
+	switch typ {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.time = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if len(s.Properties) == 0 {
+			if s.AdditionalProperties != nil && s.AdditionalProperties.Not == nil {
+				return "map[string]" + g.goType(s.AdditionalProperties)
+			}
+			return "map[string]any"
+		}
+		return g.structType(s)
+	}
+	return "any"
+}
+
+// orNull returns the other schema of an anyOf with the null type, or s
+func orNull(s *Schema) *Schema {
+	if len(s.AnyOf) != 2 {
+		return s
+	}
+	for i, alt := range s.AnyOf {
+		if len(alt.Type) == 1 && alt.Type[0] == "null" {
+			return s.AnyOf[1-i]
+		}
+	}
+	return s
+}
+
+// structType returns a struct type literal for an object schema
+func (g *clientGen) structType(s *Schema) string {
+	var sb strings.Builder
+	sb.WriteString("struct {\n")
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		ps := s.Properties[name]
+		if ps.Description != "" {
+			fmt.Fprintf(&sb, "// %s\n", ps.Description)
+		}
+
+		typ := g.goType(ps)
+		if ref := orNull(ps).Ref; ref != "" {
+			if rs := g.schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; rs != nil && rs.Type.Has("object") {
+				typ = "*" + typ
+			}
+		}
+		tag := name
+		if !slices.Contains(s.Required, name) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&sb, "%s %s `json:%q`\n", goName(name), typ, tag)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// goInitialisms are written in upper case in Go names
+var goInitialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true,
+}
+
+// goName converts a name like user_id or createUser to an exported Go
+// identifier like UserID or CreateUser
+func goName(s string) string {
+	var sb strings.Builder
+	for part := range strings.FieldsFuncSeq(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if goInitialisms[strings.ToLower(part)] {
+			sb.WriteString(strings.ToUpper(part))
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+
+	name := sb.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// lowerFirst lowers the first letter of s, or the whole of a leading
+// initialism
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	if s == strings.ToUpper(s) && goInitialisms[strings.ToLower(s)] {
+		return strings.ToLower(s)
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// clientRuntime is the fixed part of a generated client
+const clientRuntime = `// Client calls the API
+type Client struct {
+	// BaseURL of the API, like https://api.example.com
+	BaseURL string
+
+	// HTTPClient sends the requests. Default: http.DefaultClient
+	HTTPClient *http.Client
+
+	// Header is added to every request
+	Header http.Header
+}
+
+// NewClient returns a client for the API at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: http.Header{}}
+}
+
+// Error is an error response of the API
+type Error struct {
+	// Status of the response
+	Status int
+
+	// Message of the error, or the problem detail
+	Message string
+
+	// Problem is set for application/problem+json responses
+	Problem *Problem
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// Problem is an RFC 9457 problem details response
+type Problem struct {
+	Type     string      ` + "`json:\"type,omitempty\"`" + `
+	Title    string      ` + "`json:\"title\"`" + `
+	Status   int         ` + "`json:\"status\"`" + `
+	Detail   string      ` + "`json:\"detail,omitempty\"`" + `
+	Instance string      ` + "`json:\"instance,omitempty\"`" + `
+	Errors   []Violation ` + "`json:\"errors,omitempty\"`" + `
+}
+
+// Violation is an invalid value of a request
+type Violation struct {
+	In      string ` + "`json:\"in\"`" + `
+	Name    string ` + "`json:\"name,omitempty\"`" + `
+	Pointer string ` + "`json:\"pointer,omitempty\"`" + `
+	Detail  string ` + "`json:\"detail\"`" + `
+}
+
+// do sends a request with an optional JSON body and decodes a JSON
+// result into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+
+	target := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, rd)
+	if err != nil {
+		return err
+	}
+	for _, h := range []http.Header{c.Header, header} {
+		for k, vs := range h {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeError reads an error response
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	e := &Error{Status: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+
+	ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case ct == "application/problem+json":
+		var p Problem
+		if json.Unmarshal(data, &p) == nil {
+			e.Problem, e.Message = &p, p.Detail
+			if e.Message == "" {
+				e.Message = p.Title
+			}
+		}
+	case ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		var body struct {
+			Error string ` + "`json:\"error\"`" + `
+		}
+		if json.Unmarshal(data, &body) == nil && body.Error != "" {
+			e.Message = body.Error
+		}
+	}
+	return e
+}
+
+// pathValue formats and escapes a path parameter
+func pathValue(v any) string {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		b, _ := m.MarshalText()
+		return url.PathEscape(string(b))
+	}
+	return url.PathEscape(fmt.Sprint(v))
+}
+
+// addValues adds a parameter unless it is the zero value. Slices add a
+// value per element.
+func addValues(add func(key, value string), key string, v any) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.IsZero() {
+		return
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		for i := range rv.Len() {
+			addValues(add, key, rv.Index(i).Interface())
+		}
+		return
+	}
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		b, _ := m.MarshalText()
+		add(key, string(b))
+		return
+	}
+	add(key, fmt.Sprint(v))
+}
+`
@@ -0,0 +1,257 @@
+package mux
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkClient type checks a generated client
+func checkClient(t *testing.T, src []byte) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("generated client does not parse: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("client", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("generated client does not type check: %v\n%s", err, src)
+	}
+	return pkg
+}
+
+// -----------------------------------------------------------------------------
+// Client Generation
+// -----------------------------------------------------------------------------
+
+func TestGenerateClientMethods(t *testing.T) {
+	r := typedRouter()
+	r.GET("/users/{id:int}", func(c *Context) error { return nil }).Name("getUser").Response(200, user{})
+
+	src, err := r.GenerateClient(ClientConfig{Package: "items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := checkClient(t, src)
+	if pkg.Name() != "items" {
+		t.Errorf("expected package items, got %s", pkg.Name())
+	}
+
+	client := pkg.Scope().Lookup("Client").Type()
+	methods := map[string]string{
+		"GetItemsById":    "func(ctx context.Context, id int64, params *client.GetItemsByIdParams) (client.Item, error)",
+		"PostItems":       "func(ctx context.Context, body client.NewItem) (client.Created, error)",
+		"DeleteItemsById": "func(ctx context.Context, id int64, params *client.DeleteItemsByIdParams) error",
+		"GetUser":         "func(ctx context.Context, id int64) (client.User, error)",
+	}
+	for name, want := range methods {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(client), true, pkg, name)
+		if obj == nil {
+			t.Errorf("expected method %s", name)
+			continue
+		}
+		if got := obj.Type().String(); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+
+	params := pkg.Scope().Lookup("GetItemsByIdParams").Type().Underlying().String()
+	if params != "struct{Fields []string; Verbose bool; XTenant string}" {
+		t.Errorf("unexpected params %s", params)
+	}
+	if !strings.Contains(string(src), "Manager *User") {
+		t.Errorf("expected recursive reference as pointer\n%s", src)
+	}
+}
+
+func TestGenerateClientNames(t *testing.T) {
+	tests := map[string]string{
+		"createUser":   "CreateUser",
+		"user_id":      "UserID",
+		"X-Tenant":     "XTenant",
+		"mux.getItem":  "MuxGetItem",
+		"2fa":          "X2fa",
+		"api_base_url": "APIBaseURL",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q): expected %s, got %s", in, want, got)
+		}
+	}
+}
+
+func TestGenerateClientReservedNames(t *testing.T) {
+	type Error struct {
+		Code string `json:"code"`
+	}
+	r := New()
+	r.GET("/problem", func(c *Context) error { return nil }).Name("getProblem").Response(200, Problem{})
+	r.GET("/error", func(c *Context) error { return nil }).Name("getError").Response(200, Error{})
+
+	src, err := r.GenerateClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := checkClient(t, src)
+
+	for _, name := range []string{"ProblemSchema", "ErrorSchema", "ViolationSchema"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("expected type %s\n%s", name, src)
+		}
+	}
+	client := pkg.Scope().Lookup("Client").Type()
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(client), true, pkg, "GetProblem")
+	if got := obj.Type().String(); got != "func(ctx context.Context) (client.ProblemSchema, error)" {
+		t.Errorf("unexpected GetProblem %s", got)
+	}
+}
+
+func TestGenerateClientParamNames(t *testing.T) {
+	r := New()
+	r.GET("/files/{path...}", func(c *Context) error { return nil }).Name("getFile")
+	r.GET("/links/{url}/{http}/{string}", func(c *Context) error { return nil }).Name("getLink")
+	r.GET("/helpers/{path_value}/{path_param}", func(c *Context) error { return nil }).Name("getHelper")
+
+	src, err := r.GenerateClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := checkClient(t, src)
+
+	client := pkg.Scope().Lookup("Client").Type()
+	methods := map[string]string{
+		"GetFile":   "func(ctx context.Context, pathParam string) error",
+		"GetLink":   "func(ctx context.Context, urlParam string, httpParam string, stringParam string) error",
+		"GetHelper": "func(ctx context.Context, pathValueParam string, pathParam string) error",
+	}
+	for name, want := range methods {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(client), true, pkg, name)
+		if got := obj.Type().String(); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestGenerateClientNullable(t *testing.T) {
+	type team struct {
+		Lead *user `json:"lead"`
+	}
+	r := New()
+	r.GET("/team", func(c *Context) error { return nil }).Name("getTeam").Response(200, team{})
+
+	src, err := r.GenerateClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := checkClient(t, src)
+
+	fields := pkg.Scope().Lookup("Team").Type().Underlying().(*types.Struct)
+	if got := fields.Field(0).Type().String(); got != "*client.User" {
+		t.Errorf("expected nullable reference as pointer, got %s\n%s", got, src)
+	}
+}
+
+func TestGenerateClientMissingOperationID(t *testing.T) {
+	doc := &OpenAPI{Paths: map[string]*PathItem{"/": {Get: &Operation{}}}}
+	if _, err := GenerateClient(doc); err == nil {
+		t.Error("expected error for operation without id")
+	}
+}
+
+// TestGenerateClientRoundTrip builds a generated client and calls a server
+// with it
+func TestGenerateClientRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	r := typedRouter()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	src, err := r.GenerateClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example\n\ngo 1.25\n",
+		"client/client.go": string(src),
+		"main.go": `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"example/client"
+)
+
+func main() {
+	c := client.NewClient(os.Args[1])
+	ctx := context.Background()
+
+	it, err := c.GetItemsById(ctx, 7, &client.GetItemsByIdParams{Fields: []string{"a", "b"}, XTenant: os.Args[2]})
+	fmt.Println(it.ID, it.Fields, it.Tenant == os.Args[2], err)
+
+	cr, err := c.PostItems(ctx, client.NewItem{Name: "abc", Count: 1})
+	fmt.Println(cr.ID, err)
+
+	fmt.Println(c.DeleteItemsById(ctx, 1, nil))
+
+	_, err = c.GetItemsById(ctx, 404, nil)
+	var e *client.Error
+	fmt.Println(errors.As(err, &e), e.Status, e.Message, e.Problem == nil)
+
+	_, err = c.PostItems(ctx, client.NewItem{Count: 1})
+	errors.As(err, &e)
+	fmt.Println(e.Status, e.Message, e.Problem.Errors[0].Pointer)
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(gobin, "run", ".", srv.URL, tenant)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated client failed: %v\n%s", err, out)
+	}
+
+	want := strings.Join([]string{
+		"7 [a b] true <nil>",
+		"3 <nil>",
+		"<nil>",
+		"true 404 no such item true",
+		"400 request is invalid /name",
+	}, "\n") + "\n"
+	if string(out) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out)
+	}
+}
@@ -0,0 +1,77 @@
+// Command muxgen generates a Go client package from the OpenAPI document
+// of a mux router, as served by Router.OpenAPIHandler.
+//
+// Usage:
+//
+//	muxgen [-pkg name] [-o file] <openapi.json | URL>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bamatar/mux"
+)
+
+func main() {
+	pkg := flag.String("pkg", "client", "package name of the generated client")
+	out := flag.String("o", "", "output file (default: stdout)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: muxgen [-pkg name] [-o file] <openapi.json | URL>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *pkg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "muxgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(source, pkg, out string) error {
+	data, err := read(source)
+	if err != nil {
+		return err
+	}
+	doc, err := mux.ParseOpenAPI(data)
+	if err != nil {
+		return err
+	}
+	src, err := mux.GenerateClient(doc, mux.ClientConfig{Package: pkg})
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// read returns the document at a URL, a file or stdin for "-"
+func read(source string) ([]byte, error) {
+	switch {
+	case source == "-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("get %s: %s", source, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return os.ReadFile(source)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"github.com/bamatar/mux/internal/load"
+)
+
+// registrations maps the Router and Group methods that register routes
+// to their HTTP method
+var registrations = map[string]string{
+	"GET":    "GET",
+	"POST":   "POST",
+	"PUT":    "PUT",
+	"DELETE": "DELETE",
+	"PATCH":  "PATCH",
+	"Mount":  "",
+}
+
+// extract returns the routes registered by the packages matching the
+// patterns, in source order
+func extract(dir string, patterns ...string) ([]route, error) {
+	pkgs, err := load.Packages(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []route
+	for _, pkg := range pkgs {
+		w := &walker{pkg: pkg, info: pkg.Info, groups: make(map[types.Object]group)}
+		for _, f := range pkg.Files {
+			ast.Inspect(f, w.visit)
+		}
+		routes = append(routes, w.routes...)
+	}
+	return routes, nil
+}
+
+// group is what is known statically about a Router or Group value
+type group struct {
+	prefix  string
+	host    string
+	version string
+	unknown bool
+}
+
+// walker collects the routes of a package
+type walker struct {
+	pkg    *load.Package
+	info   *types.Info
+	groups map[types.Object]group
+	routes []route
+
+	// names are route names chained on registration calls
+	names map[*ast.CallExpr]string
+}
+
+func (w *walker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.AssignStmt, *ast.ValueSpec:
+		load.Assignments(n, w.assign)
+
+	case *ast.CallExpr:
+		sel, recv, ok := w.muxMethod(n)
+		if !ok {
+			break
+		}
+		switch sel.Sel.Name {
+		case "Name":
+			// rt.Name("x") on a registration call names its route
+			if call, ok := ast.Unparen(sel.X).(*ast.CallExpr); ok && len(n.Args) == 1 {
+				if w.names == nil {
+					w.names = make(map[*ast.CallExpr]string)
+				}
+				w.names[call] = w.stringValue(n.Args[0])
+			}
+
+		case "Version":
+			// the group parameter of the callback is a version group
+			if len(n.Args) != 2 {
+				break
+			}
+			fn, ok := ast.Unparen(n.Args[1]).(*ast.FuncLit)
+			if !ok || len(fn.Type.Params.List) != 1 || len(fn.Type.Params.List[0].Names) != 1 {
+				break
+			}
+			g := w.group(recv)
+			g.version = w.stringValue(n.Args[0])
+			w.groups[w.info.Defs[fn.Type.Params.List[0].Names[0]]] = g
+
+		default:
+			method, ok := registrations[sel.Sel.Name]
+			if !ok || len(n.Args) < 2 {
+				break
+			}
+			g := w.group(recv)
+			pattern := g.prefix + w.stringValue(n.Args[0])
+			if g.unknown {
+				pattern = "..." + pattern
+			}
+			w.routes = append(w.routes, route{
+				Method:  method,
+				Host:    g.host,
+				Pattern: pattern,
+				Version: g.version,
+				Handler: w.handlerName(n.Args[1]),
+				Pos:     w.pkg.Position(n.Pos()),
+				Name:    w.names[n],
+			})
+		}
+	}
+	return true
+}
+
+// assign records the group a variable is assigned
+func (w *walker) assign(lhs, rhs ast.Expr) {
+	id, ok := ast.Unparen(lhs).(*ast.Ident)
+	if !ok || !load.IsMuxType(w.info.TypeOf(rhs), "Group") {
+		return
+	}
+	obj := w.info.Defs[id]
+	if obj == nil {
+		obj = w.info.Uses[id]
+	}
+	if obj != nil {
+		w.groups[obj] = w.group(rhs)
+	}
+}
+
+// group returns what is known about the Router or Group expression
+func (w *walker) group(expr ast.Expr) group {
+	expr = ast.Unparen(expr)
+	if load.IsMuxType(w.info.TypeOf(expr), "Router") {
+		return group{}
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if g, ok := w.groups[w.info.Uses[e]]; ok {
+			return g
+		}
+	case *ast.CallExpr:
+		sel, recv, ok := w.muxMethod(e)
+		if !ok || len(e.Args) != 1 {
+			break
+		}
+		switch sel.Sel.Name {
+		case "Group":
+			g := w.group(recv)
+			g.prefix += w.stringValue(e.Args[0])
+			return g
+		case "Host":
+			return group{host: strings.ToLower(w.stringValue(e.Args[0]))}
+		}
+	}
+	return group{unknown: true}
+}
+
+// muxMethod reports whether call calls a method of mux.Router or
+// mux.Group, or Route.Name, and returns the receiver expression
+func (w *walker) muxMethod(call *ast.CallExpr) (*ast.SelectorExpr, ast.Expr, bool) {
+	sel, recv, ok := load.MuxMethod(w.info, call)
+	if !ok || recv == "Context" || recv == "Route" && sel.Sel.Name != "Name" {
+		return nil, nil, false
+	}
+	return sel, sel.X, true
+}
+
+// stringValue returns the constant string value of expr, or its source
+// for non-constant expressions
+func (w *walker) stringValue(expr ast.Expr) string {
+	if tv, ok := w.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	return "{" + types.ExprString(expr) + "}"
+}
+
+// handlerName names the handler expression like Routes does at runtime
+func (w *walker) handlerName(expr ast.Expr) string {
+	expr = ast.Unparen(expr)
+	switch e := expr.(type) {
+	case *ast.FuncLit:
+		return "func literal"
+	case *ast.CallExpr:
+		// adapters like Typed(fn) and HandlerFunc(fn) are named after fn
+		if len(e.Args) == 1 {
+			if name := w.funcName(e.Args[0]); name != "" {
+				return name
+			}
+		}
+	}
+	if name := w.funcName(expr); name != "" {
+		return name
+	}
+	return types.ExprString(expr)
+}
+
+// funcName returns pkg.Func or pkg.(*T).Method for a function or method
+// value expression
+func (w *walker) funcName(expr ast.Expr) string {
+	var id *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	case *ast.IndexExpr:
+		return w.funcName(e.X)
+	default:
+		return ""
+	}
+
+	fn, ok := w.info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		return fn.Pkg().Name() + "." + fn.Name()
+	}
+
+	recv := sig.Recv().Type()
+	ptr := ""
+	if p, ok := recv.(*types.Pointer); ok {
+		recv, ptr = p.Elem(), "*"
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return fn.Pkg().Name() + "." + fn.Name()
+	}
+	if ptr != "" {
+		return fmt.Sprintf("%s.(*%s).%s", fn.Pkg().Name(), named.Obj().Name(), fn.Name())
+	}
+	return fmt.Sprintf("%s.%s.%s", fn.Pkg().Name(), named.Obj().Name(), fn.Name())
+}
@@ -0,0 +1,102 @@
+// Command muxroutes prints the routes a Go module registers on mux
+// routers without running it. It type checks the packages and follows
+// Group, Host and Version prefixes through variable assignments.
+//
+// Usage:
+//
+//	muxroutes [-format text|json|markdown] [packages]
+//
+// Routes registered on a group muxroutes cannot resolve, like a *Group
+// function parameter, are printed with a "..." prefix.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text, json or markdown")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: muxroutes [-format text|json|markdown] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	routes, err := extract(".", patterns...)
+	if err == nil {
+		err = write(os.Stdout, *format, routes)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "muxroutes: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// write prints routes in the format
+func write(w io.Writer, format string, routes []route) error {
+	switch format {
+	case "json":
+		if routes == nil {
+			routes = []route{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+
+	case "markdown", "md":
+		fmt.Fprintln(w, "| Method | Pattern | Handler | Position |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- |")
+		for _, rt := range routes {
+			fmt.Fprintf(w, "| %s | `%s` | `%s` | %s |\n", rt.method(), rt.fullPattern(), rt.Handler, rt.Pos)
+		}
+		return nil
+
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, rt := range routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rt.method(), rt.fullPattern(), rt.Handler, rt.Pos)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// route is a route registration found in the source
+type route struct {
+	Method  string `json:"method"`
+	Host    string `json:"host,omitempty"`
+	Pattern string `json:"pattern"`
+	Version string `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Handler string `json:"handler"`
+	Pos     string `json:"pos"`
+}
+
+// method returns the method, or * for mounts
+func (rt route) method() string {
+	if rt.Method == "" {
+		return "*"
+	}
+	return rt.Method
+}
+
+// fullPattern returns the pattern with the host and version
+func (rt route) fullPattern() string {
+	var sb strings.Builder
+	sb.WriteString(rt.Host)
+	sb.WriteString(rt.Pattern)
+	if rt.Version != "" {
+		fmt.Fprintf(&sb, " (v%s)", rt.Version)
+	}
+	return sb.String()
+}
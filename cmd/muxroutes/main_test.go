@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const app = `package app
+
+import (
+	"net/http"
+
+	"github.com/bamatar/mux"
+)
+
+type server struct{}
+
+func (s *server) list(c *mux.Context) error { return nil }
+
+type page struct {
+	ID int ` + "`path:\"id\"`" + `
+}
+
+func getPage(c *mux.Context, in page) (page, error) { return in, nil }
+
+func health(c *mux.Context) error { return nil }
+
+const usersPath = "/users"
+
+func Routes(s *server) *mux.Router {
+	r := mux.New()
+	r.GET("/health", health).Name("health")
+
+	api := r.Group("/api")
+	users := api.Group(usersPath)
+	users.GET("", s.list)
+	users.POST("/{id}", func(c *mux.Context) error { return nil })
+
+	var pages *mux.Group
+	pages = api.Group("/pages")
+	pages.GET("/{id:int}", mux.Typed(getPage))
+
+	r.Host("Admin.example.com").DELETE("/cache", health)
+	r.Version("2", func(g *mux.Group) {
+		g.PATCH("/things", health)
+	})
+	r.Mount("/static", http.FileServer(http.Dir(".")))
+
+	register(api)
+	return r
+}
+
+func register(g *mux.Group) {
+	g.PUT("/settings", health)
+}
+`
+
+// module writes a module using mux to a temporary directory
+func module(t *testing.T) string {
+	t.Helper()
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example\n\ngo 1.25\n\nrequire github.com/bamatar/mux v0.0.0\n\nreplace github.com/bamatar/mux => " + root + "\n",
+		"app/app.go": app,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// -----------------------------------------------------------------------------
+// Extraction
+// -----------------------------------------------------------------------------
+
+func TestExtract(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	routes, err := extract(module(t), "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []route{
+		{Method: "GET", Pattern: "/health", Name: "health", Handler: "app.health"},
+		{Method: "GET", Pattern: "/api/users", Handler: "app.(*server).list"},
+		{Method: "POST", Pattern: "/api/users/{id}", Handler: "func literal"},
+		{Method: "GET", Pattern: "/api/pages/{id:int}", Handler: "app.getPage"},
+		{Method: "DELETE", Host: "admin.example.com", Pattern: "/cache", Handler: "app.health"},
+		{Method: "PATCH", Pattern: "/things", Version: "2", Handler: "app.health"},
+		{Method: "", Pattern: "/static", Handler: `http.FileServer(http.Dir("."))`},
+		{Method: "PUT", Pattern: ".../settings", Handler: "app.health"},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("expected %d routes, got %+v", len(want), routes)
+	}
+	for i, rt := range routes {
+		if !strings.Contains(rt.Pos, "app.go:") {
+			t.Errorf("expected source position, got %q", rt.Pos)
+		}
+		rt.Pos = ""
+		if rt != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], rt)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	routes := []route{
+		{Method: "GET", Pattern: "/users/{id}", Handler: "app.getUser", Pos: "app.go:10:2"},
+		{Pattern: "/static", Version: "2", Host: "example.com", Handler: "http.FileServer", Pos: "app.go:11:2"},
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, "text", routes); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET  /users/{id}              app.getUser      app.go:10:2\n" +
+		"*    example.com/static (v2)  http.FileServer  app.go:11:2\n"
+	if buf.String() != want {
+		t.Errorf("expected\n%q\ngot\n%q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := write(&buf, "markdown", routes); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "| GET | `/users/{id}` | `app.getUser` | app.go:10:2 |") {
+		t.Errorf("unexpected markdown %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := write(&buf, "json", nil); err != nil || buf.String() != "[]\n" {
+		t.Errorf("expected empty JSON list, got %q %v", buf.String(), err)
+	}
+	buf.Reset()
+	_ = write(&buf, "json", routes)
+	var decoded []route
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded[1] != routes[1] {
+		t.Errorf("expected JSON round trip, got %s", buf.String())
+	}
+
+	if err := write(&buf, "yaml", routes); err == nil {
+		t.Error("expected unknown format error")
+	}
+}
@@ -0,0 +1,45 @@
+// Command muxvet reports common mistakes in code using mux:
+//
+//   - a handler's Context used in a goroutine, which can outlive the
+//     request while the Context is reused for another one
+//   - a second response written after a handler already wrote one
+//   - an ignored error from Context.Bind or Context.BindRequest
+//   - a route registered before a Use call on its router or group, which
+//     only applies to the routes registered after it
+//
+// Usage:
+//
+//	muxvet [packages]
+//
+// It exits with status 1 when it reports a problem.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: muxvet [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	findings, err := vet(".", patterns...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "muxvet: %v\n", err)
+		os.Exit(2)
+	}
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", f.Pos, f.Message)
+	}
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const app = `package app
+
+import "github.com/bamatar/mux"
+
+type input struct{ Name string }
+
+func async(c *mux.Context) error {
+	go func() {
+		_ = c.JSON(200, nil) // goroutine
+	}()
+	return nil
+}
+
+func spawn(c *mux.Context) error {
+	go notify(c) // goroutine
+	return c.NoContent()
+}
+
+func notify(c *mux.Context) {}
+
+func copied(c *mux.Context) error {
+	go notify(c.Copy())
+	go func(cc *mux.Context) {
+		_ = cc.JSON(200, nil)
+	}(c.Copy())
+	go c.NoContent() // goroutine
+	return nil
+}
+
+func twice(c *mux.Context) error {
+	c.JSON(200, mux.M{"a": 1})
+	return c.JSON(200, mux.M{"b": 2}) // twice
+}
+
+func branches(c *mux.Context) error {
+	if c.Query("x") != "" {
+		return c.JSON(200, nil)
+	}
+	if err := c.OK(nil); err != nil {
+		return err
+	}
+	return c.NoContent() // twice
+}
+
+func bind(c *mux.Context) error {
+	var in input
+	c.Bind(&in)  // bind
+	_ = c.BindRequest(&in) // bind
+	if err := c.Bind(&in); err != nil {
+		return err
+	}
+	return c.OK(in)
+}
+
+func logger(next mux.Handler) mux.Handler { return next }
+
+func Routes() *mux.Router {
+	r := mux.New()
+	r.GET("/early", async) // order
+	r.Group("/anon").GET("/early", async) // order
+
+	api := r.Group("/api")
+	api.GET("/early", async) // order
+	var ops = r.Group("/ops")
+	ops.GET("/early", async) // order
+	r.Use(logger)
+	r.GET("/late", async)
+
+	api.POST("/group-early", async) // order
+	api.Use(logger)
+	api.POST("/group-late", async)
+
+	admin := api.Group("/admin")
+	admin.Use(logger)
+	admin.PUT("/late", async)
+
+	r.Version("2", func(g *mux.Group) {
+		g.PATCH("/v", async) // order
+		g.Use(logger)
+	})
+	return r
+}
+`
+
+// -----------------------------------------------------------------------------
+// Checks
+// -----------------------------------------------------------------------------
+
+func TestVet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	mod := "module example\n\ngo 1.25\n\nrequire github.com/bamatar/mux v0.0.0\n\nreplace github.com/bamatar/mux => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(app), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := vet(dir, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every line marked with a comment gets a finding of its kind
+	lines := strings.Split(app, "\n")
+	want := make(map[int]string)
+	for i, line := range lines {
+		if _, mark, ok := strings.Cut(line, "// "); ok && strings.Contains("goroutine twice bind order", mark) {
+			want[i+1] = mark
+		}
+	}
+	kinds := map[string]string{
+		"goroutine": "used in a goroutine",
+		"twice":     "already wrote one",
+		"bind":      "is ignored",
+		"order":     "does not get its middleware",
+	}
+
+	for _, f := range findings {
+		parts := strings.Split(f.Pos, ":")
+		line, _ := strconv.Atoi(parts[len(parts)-2])
+		mark, ok := want[line]
+		if !ok {
+			t.Errorf("unexpected finding %s: %s", f.Pos, f.Message)
+			continue
+		}
+		if !strings.Contains(f.Message, kinds[mark]) {
+			t.Errorf("%s: expected %s finding, got %s", f.Pos, mark, f.Message)
+		}
+		delete(want, line)
+	}
+	for line, mark := range want {
+		t.Errorf("line %d: missing %s finding: %s", line, mark, strings.TrimSpace(lines[line-1]))
+	}
+}
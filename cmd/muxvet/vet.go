@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"github.com/bamatar/mux/internal/load"
+)
+
+// finding is a reported problem
+type finding struct {
+	Pos     string
+	Message string
+
+	pos token.Pos
+}
+
+// writers are the Context methods that write a response
+var writers = map[string]bool{
+	"Status": true, "NoContent": true, "JSON": true, "OK": true, "Created": true,
+	"BadRequest": true, "Unauthorized": true, "Forbidden": true, "NotFound": true,
+	"MethodNotAllowed": true, "InternalServerError": true, "Problem": true,
+	"String": true, "HTML": true, "Blob": true, "Render": true,
+}
+
+// registrations are the Router and Group methods that register routes
+var registrations = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "Mount": true,
+}
+
+// vet checks the packages matching the patterns in dir
+func vet(dir string, patterns ...string) ([]finding, error) {
+	pkgs, err := load.Packages(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+	for _, pkg := range pkgs {
+		c := &checker{pkg: pkg, info: pkg.Info}
+		for _, f := range pkg.Files {
+			c.file(f)
+		}
+		slices.SortStableFunc(c.findings, func(a, b finding) int { return int(a.pos - b.pos) })
+		findings = append(findings, c.findings...)
+	}
+	return findings, nil
+}
+
+// checker runs the checks on the files of a package
+type checker struct {
+	pkg      *load.Package
+	info     *types.Info
+	findings []finding
+}
+
+func (c *checker) report(pos token.Pos, format string, args ...any) {
+	c.findings = append(c.findings, finding{
+		Pos:     c.pkg.Position(pos),
+		Message: fmt.Sprintf(format, args...),
+		pos:     pos,
+	})
+}
+
+func (c *checker) file(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				c.contextParams(n.Type, n.Body)
+				c.useOrder(n.Body)
+			}
+		case *ast.FuncLit:
+			c.contextParams(n.Type, n.Body)
+		case *ast.BlockStmt:
+			c.writes(n)
+		case *ast.ExprStmt:
+			if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+				c.ignoredBind(call)
+			}
+		case *ast.AssignStmt:
+			if len(n.Rhs) == 1 && len(n.Lhs) == 1 && isBlank(n.Lhs[0]) {
+				if call, ok := ast.Unparen(n.Rhs[0]).(*ast.CallExpr); ok {
+					c.ignoredBind(call)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// contextParams reports the Context parameters of a function used in a
+// go statement of its body
+func (c *checker) contextParams(typ *ast.FuncType, body *ast.BlockStmt) {
+	var params []types.Object
+	for _, field := range typ.Params.List {
+		for _, name := range field.Names {
+			if obj := c.info.Defs[name]; obj != nil && isContext(obj.Type()) {
+				params = append(params, obj)
+			}
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.GoStmt)
+		if !ok {
+			return true
+		}
+		reported := make(map[types.Object]bool)
+		check := func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := c.info.Uses[id]
+			if slices.Contains(params, obj) && !reported[obj] {
+				reported[obj] = true
+				c.report(id.Pos(), "%s is used in a goroutine that can outlive the request; the Context is reused once the handler returns, use %s.Copy or %s.Go", id.Name, id.Name, id.Name)
+			}
+			return true
+		}
+
+		// arguments are evaluated before the goroutine starts, so only
+		// the Context itself passed along, or used as the receiver or in
+		// the body of the function run, escapes: go work(c.Copy()) is fine
+		switch fun := ast.Unparen(stmt.Call.Fun).(type) {
+		case *ast.FuncLit:
+			ast.Inspect(fun.Body, check)
+		case *ast.SelectorExpr:
+			check(ast.Unparen(fun.X))
+		}
+		for _, arg := range stmt.Call.Args {
+			check(ast.Unparen(arg))
+		}
+		return true
+	})
+}
+
+// writes reports a response written after a statement of the same block
+// already wrote one
+func (c *checker) writes(block *ast.BlockStmt) {
+	written := make(map[types.Object]string)
+	for _, stmt := range block.List {
+		for _, call := range stmtCalls(stmt) {
+			sel, recv, ok := load.MuxMethod(c.info, call)
+			if !ok || recv != "Context" || !writers[sel.Sel.Name] {
+				continue
+			}
+			id, ok := ast.Unparen(sel.X).(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := c.info.Uses[id]
+			if prev, ok := written[obj]; ok {
+				c.report(call.Pos(), "%s.%s writes a response after %s.%s already wrote one", id.Name, sel.Sel.Name, id.Name, prev)
+				continue
+			}
+			written[obj] = sel.Sel.Name
+		}
+	}
+}
+
+// stmtCalls returns the calls a statement always makes at its top level
+func stmtCalls(stmt ast.Stmt) []*ast.CallExpr {
+	var exprs []ast.Expr
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		exprs = []ast.Expr{s.X}
+	case *ast.AssignStmt:
+		exprs = s.Rhs
+	case *ast.ReturnStmt:
+		exprs = s.Results
+	case *ast.IfStmt:
+		if s.Init != nil {
+			return stmtCalls(s.Init)
+		}
+	}
+
+	var calls []*ast.CallExpr
+	for _, e := range exprs {
+		if call, ok := ast.Unparen(e).(*ast.CallExpr); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// ignoredBind reports a bind call whose error is discarded
+func (c *checker) ignoredBind(call *ast.CallExpr) {
+	sel, recv, ok := load.MuxMethod(c.info, call)
+	if ok && recv == "Context" && (sel.Sel.Name == "Bind" || sel.Sel.Name == "BindRequest") {
+		c.report(call.Pos(), "error returned by %s is ignored; the request may be partly bound", types.ExprString(sel))
+	}
+}
+
+// registration is a route registered on a router or group
+type registration struct {
+	pos      token.Pos
+	on       types.Object
+	owners   []types.Object
+	reported bool
+}
+
+// useOrder reports routes registered before a Use call on their router,
+// or on the group they are registered on, since middleware only wraps
+// the routes registered after it
+func (c *checker) useOrder(body *ast.BlockStmt) {
+	// parents maps groups to the router or group they were created from
+	parents := make(map[types.Object]types.Object)
+	var regs []*registration
+
+	owners := func(expr ast.Expr) (types.Object, []types.Object) {
+		var on types.Object
+		for {
+			switch e := ast.Unparen(expr).(type) {
+			case *ast.Ident:
+				obj := c.info.Uses[e]
+				if on == nil {
+					on = obj
+				}
+				list := []types.Object{obj}
+				for p := parents[obj]; p != nil && !slices.Contains(list, p); p = parents[p] {
+					list = append(list, p)
+				}
+				return on, list
+			case *ast.CallExpr:
+				// r.Group("/x").GET is registered on a new group of r
+				sel, _, ok := load.MuxMethod(c.info, e)
+				if !ok {
+					return on, nil
+				}
+				if on == nil {
+					on = types.NewVar(e.Pos(), nil, "", nil)
+				}
+				expr = sel.X
+			default:
+				return on, nil
+			}
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt, *ast.ValueSpec:
+			load.Assignments(n, func(lhs, rhs ast.Expr) {
+				id, ok := lhs.(*ast.Ident)
+				call, isCall := ast.Unparen(rhs).(*ast.CallExpr)
+				if !ok || !isCall || !load.IsMuxType(c.info.TypeOf(call), "Group") {
+					return
+				}
+				sel, _, ok := load.MuxMethod(c.info, call)
+				if !ok {
+					return
+				}
+				obj := c.info.Defs[id]
+				if obj == nil {
+					obj = c.info.Uses[id]
+				}
+				if _, list := owners(sel.X); len(list) > 0 {
+					parents[obj] = list[0]
+				}
+			})
+
+		case *ast.CallExpr:
+			sel, recv, ok := load.MuxMethod(c.info, n)
+			if !ok || recv != "Router" && recv != "Group" {
+				break
+			}
+
+			switch name := sel.Sel.Name; {
+			case name == "Version":
+				// the callback's group is created from the receiver
+				if len(n.Args) != 2 {
+					break
+				}
+				fn, ok := ast.Unparen(n.Args[1]).(*ast.FuncLit)
+				if !ok || len(fn.Type.Params.List) != 1 || len(fn.Type.Params.List[0].Names) != 1 {
+					break
+				}
+				if _, list := owners(sel.X); len(list) > 0 {
+					parents[c.info.Defs[fn.Type.Params.List[0].Names[0]]] = list[0]
+				}
+
+			case registrations[name]:
+				on, list := owners(sel.X)
+				regs = append(regs, &registration{pos: n.Pos(), on: on, owners: list})
+
+			case name == "Use":
+				id, ok := ast.Unparen(sel.X).(*ast.Ident)
+				if !ok {
+					break
+				}
+				obj := c.info.Uses[id]
+				for _, reg := range regs {
+					if reg.reported {
+						continue
+					}
+					if reg.on == obj || recv == "Router" && slices.Contains(reg.owners, obj) {
+						reg.reported = true
+						c.report(reg.pos, "route is registered before %s.Use at %s and does not get its middleware", id.Name, c.pkg.Position(n.Pos()))
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// isContext reports whether t is *mux.Context
+func isContext(t types.Type) bool {
+	_, ok := t.(*types.Pointer)
+	return ok && load.IsMuxType(t, "Context")
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
@@ -0,0 +1,308 @@
+package mux
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// constraint restricts the values a path wildcard matches
+type constraint struct {
+	name  string
+	match func(string) bool
+}
+
+// constraints are the wildcard constraints of a pattern
+type constraints []constraint
+
+// parseConstraints strips {name:constraint} wildcards in pattern down to
+// {name} and returns the constraints. A constraint is int, uuid or a
+// regular expression matching the whole segment.
+func parseConstraints(pattern string) (string, constraints) {
+	var sb strings.Builder
+	var cons constraints
+
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 {
+			break
+		}
+		end := closingBrace(pattern, i)
+		if end < 0 {
+			break
+		}
+
+		name, expr, found := strings.Cut(pattern[i+1:end], ":")
+		sb.WriteString(pattern[:i+1])
+		sb.WriteString(name)
+		sb.WriteByte('}')
+		pattern = pattern[end+1:]
+
+		if found {
+			cons = append(cons, constraint{
+				name:  strings.TrimSuffix(name, "..."),
+				match: constraintFunc(expr),
+			})
+		}
+	}
+	sb.WriteString(pattern)
+
+	return sb.String(), cons
+}
+
+// closingBrace returns the index of the brace closing the one at i
+func closingBrace(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// constraintFunc returns the matcher for a constraint expression
+func constraintFunc(expr string) func(string) bool {
+	switch expr {
+	case "int":
+		return func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}
+	case "uuid":
+		return func(s string) bool {
+			_, err := ParseUUID(s)
+			return err == nil
+		}
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("mux: invalid constraint %q: %v", expr, err))
+	}
+	return re.MatchString
+}
+
+// match reports whether the path values of req satisfy the constraints
+func (cons constraints) match(req *http.Request) bool {
+	for _, con := range cons {
+		if !con.match(req.PathValue(con.name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// siblings dispatches the routes registered with a matcher as one
+// pattern: the versions of a route selected by header, Accept or the
+// default version, and, for matchers that do not evaluate constraints,
+// routes that only differ in wildcard names and constraints, like
+// /users/{id:int} and /users/{slug:[a-z]+}. The routes of the requested
+// version are tried first, then the unversioned ones; constrained routes
+// before the one without constraints, in registration order.
+type siblings struct {
+	router *Router
+	table  *table
+
+	// wildcard names of the pattern registered with the matcher
+	names  []string
+	routes []sibling
+
+	// whether a route is registered for a version
+	versioned bool
+}
+
+// sibling is a route of siblings
+type sibling struct {
+	pattern string
+	version string
+	names   []string
+	rename  bool
+	cons    constraints
+	h       http.Handler
+}
+
+// rank orders siblings: versioned before unversioned, constrained before
+// unconstrained
+func (sb *sibling) rank() int {
+	rank := 0
+	if sb.version == "" {
+		rank += 2
+	}
+	if len(sb.cons) == 0 {
+		rank++
+	}
+	return rank
+}
+
+// handlePattern registers hf for the matcher key of pattern, for the
+// version or unversioned, through the dispatcher of the routes sharing
+// the key
+func (r *Router) handlePattern(t *table, mux Matcher, g *Group, pattern, key, version string, cons constraints, hf http.Handler) {
+	// matchers that evaluate constraints tell wildcard names apart
+	id, names := key, []string(nil)
+	if _, ok := mux.(constraintMatcher); !ok {
+		id, names = patternShape(key)
+	}
+	if g != nil && g.host != nil {
+		id = g.host.pattern + " " + id
+	}
+
+	p := t.patterns[id]
+	if p == nil {
+		p = &siblings{router: r, table: t, names: names}
+		if t.patterns == nil {
+			t.patterns = make(map[string]*siblings)
+		}
+		t.patterns[id] = p
+		mux.Handle(key, p)
+	}
+
+	sb := sibling{pattern: pattern, version: version, names: names, rename: !slices.Equal(names, p.names), cons: cons, h: hf}
+	i := 0
+	for ; i < len(p.routes) && p.routes[i].rank() <= sb.rank(); i++ {
+		prev := &p.routes[i]
+		if len(cons) > 0 || len(prev.cons) > 0 || prev.version != version {
+			continue
+		}
+		if version != "" {
+			panic(fmt.Sprintf("mux: pattern %q registered twice for version %s", pattern, version))
+		}
+		panic(fmt.Sprintf("mux: pattern %q conflicts with %q", pattern, prev.pattern))
+	}
+	p.routes = slices.Insert(p.routes, i, sb)
+	p.versioned = p.versioned || version != ""
+}
+
+func (p *siblings) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var version string
+	var status int
+	if p.versioned {
+		version, status = p.router.selectVersion(p.table, w, req)
+	}
+
+	var values []string
+	for i := range p.routes {
+		sb := &p.routes[i]
+		if sb.version != "" && (status != 0 || sb.version != version) {
+			continue
+		}
+		if sb.rename {
+			// the wildcards have the names of the registered pattern
+			if values == nil {
+				values = make([]string, len(p.names))
+				for j, name := range p.names {
+					values[j] = req.PathValue(name)
+				}
+			}
+			for j, name := range sb.names {
+				req.SetPathValue(name, values[j])
+			}
+		}
+		if sb.cons.match(req) {
+			sb.h.ServeHTTP(w, req)
+			return
+		}
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		p.router.badVersion.ServeHTTP(w, req)
+	case http.StatusNotAcceptable:
+		p.router.badAccept.ServeHTTP(w, req)
+	default:
+		p.router.notFound(p.table, w, req, p.table.matchHost(req.Host))
+	}
+}
+
+// patternShape returns a matcher key without its wildcard names, and the
+// names in order
+func patternShape(key string) (string, []string) {
+	var sb strings.Builder
+	var names []string
+
+	for {
+		i := strings.IndexByte(key, '{')
+		if i < 0 {
+			break
+		}
+		end := closingBrace(key, i)
+		if end < 0 {
+			break
+		}
+
+		name := key[i+1 : end]
+		sb.WriteString(key[:i+1])
+		if name == "$" {
+			sb.WriteString(name)
+		} else if base, ok := strings.CutSuffix(name, "..."); ok {
+			sb.WriteString("...")
+			names = append(names, base)
+		} else {
+			names = append(names, name)
+		}
+		sb.WriteByte('}')
+		key = key[end+1:]
+	}
+	sb.WriteString(key)
+
+	return sb.String(), names
+}
+
+// UUID is a parsed RFC 9562 UUID
+type UUID [16]byte
+
+// ParseUUID parses a UUID in the canonical 8-4-4-4-12 hex form
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.New("invalid UUID format")
+	}
+
+	b := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(u[:], b); err != nil {
+		return u, errors.New("invalid UUID format")
+	}
+	return u, nil
+}
+
+// String returns the canonical form of the UUID
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// MarshalText encodes the UUID in its canonical form
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText parses a UUID in its canonical form
+func (u *UUID) UnmarshalText(text []byte) error {
+	v, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
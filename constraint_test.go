@@ -0,0 +1,265 @@
+package mux
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Constraint Parsing
+// -----------------------------------------------------------------------------
+
+func TestParseConstraints(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		names   []string
+	}{
+		{"/users/{id}", "/users/{id}", nil},
+		{"/users/{id:int}", "/users/{id}", []string{"id"}},
+		{"/posts/{slug:[a-z-]+}/{id:uuid}", "/posts/{slug}/{id}", []string{"slug", "id"}},
+		{"/codes/{code:[0-9]{3}}", "/codes/{code}", []string{"code"}},
+		{"/files/{path...:.+\\.txt}", "/files/{path...}", []string{"path"}},
+	}
+
+	for _, tt := range tests {
+		got, cons := parseConstraints(tt.pattern)
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.pattern, tt.want, got)
+		}
+		if len(cons) != len(tt.names) {
+			t.Errorf("%s: expected %d constraints, got %d", tt.pattern, len(tt.names), len(cons))
+			continue
+		}
+		for i, name := range tt.names {
+			if cons[i].name != name {
+				t.Errorf("%s: expected constraint %s, got %s", tt.pattern, name, cons[i].name)
+			}
+		}
+	}
+}
+
+func TestParseConstraintsInvalidRegexp(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid constraint")
+		}
+	}()
+	parseConstraints("/users/{id:[}")
+}
+
+// -----------------------------------------------------------------------------
+// Constraint Matching
+// -----------------------------------------------------------------------------
+
+func TestConstraintInt(t *testing.T) {
+	r := New()
+	r.GET("/users/{id:int}", func(c *Context) error {
+		return c.OK(M{"id": c.ParamInt("id")})
+	})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/users/42", 200},
+		{"/users/-7", 200},
+		{"/users/abc", 404},
+		{"/users/4.2", 404},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestConstraintUUID(t *testing.T) {
+	r := New()
+	r.GET("/orders/{id:uuid}", func(c *Context) error {
+		return c.OK(M{"id": c.ParamUUID("id")})
+	})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/orders/6ba7b810-9dad-11d1-80b4-00c04fd430c8", 200},
+		{"/orders/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", 200},
+		{"/orders/6ba7b810-9dad-11d1-80b4", 404},
+		{"/orders/6ba7b810x9dad-11d1-80b4-00c04fd430c8", 404},
+		{"/orders/zba7b810-9dad-11d1-80b4-00c04fd430c8", 404},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestConstraintRegexp(t *testing.T) {
+	r := New()
+	r.GET("/posts/{slug:[a-z-]+}", func(c *Context) error {
+		return c.OK(M{"slug": c.Param("slug")})
+	})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/posts/hello-world", 200},
+		{"/posts/Hello", 404},
+		{"/posts/hello1", 404},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestConstraintMismatchSkipsMiddleware(t *testing.T) {
+	r := New()
+
+	var called bool
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			called = true
+			return next(c)
+		}
+	})
+	r.GET("/users/{id:int}", func(c *Context) error {
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/users/abc", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if called {
+		t.Error("middleware should not run when a constraint fails")
+	}
+}
+
+func TestConstraintMismatchGroup404(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "api"})
+	})
+	api.GET("/users/{id:int}", func(c *Context) error {
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/api/users/abc", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if rec.Code != 404 || body["error"] != "api" {
+		t.Errorf("expected group 404, got %d %v", rec.Code, body)
+	}
+}
+
+func TestConstraintSiblings(t *testing.T) {
+	r := New()
+	r.GET("/users/{id:int}", func(c *Context) error {
+		return c.String(200, "id "+c.Param("id"))
+	})
+	r.GET("/users/{name}", func(c *Context) error {
+		return c.String(200, "name "+c.Param("name"))
+	})
+	r.GET("/users/{slug:[a-z]+}", func(c *Context) error {
+		return c.String(200, "slug "+c.Param("slug"))
+	})
+
+	tests := map[string]string{
+		"/users/42":   "id 42",
+		"/users/jane": "slug jane",
+		"/users/J-1":  "name J-1",
+	}
+	for path, want := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Body.String() != want {
+			t.Errorf("%s: expected %q, got %q", path, want, rec.Body.String())
+		}
+	}
+}
+
+func TestConstraintSiblingsNotFound(t *testing.T) {
+	r := New()
+	r.GET("/users/{id:int}", listUsers)
+	r.GET("/users/{slug:[a-z]+}", listUsers)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/users/J-1", nil))
+	if rec.Code != 404 {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestConstraintSiblingsConflict(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", listUsers)
+
+	e := recovered(func() { r.GET("/users/{name}", listUsers) })
+	if msg, _ := e.(string); !strings.Contains(msg, "conflicts with") {
+		t.Errorf("expected conflict panic, got %v", e)
+	}
+}
+
+func TestConstraintRoutePattern(t *testing.T) {
+	r := New()
+	r.GET("/users/{id:int}", listUsers)
+
+	if p := r.Routes()[0].Pattern; p != "/users/{id:int}" {
+		t.Errorf("expected /users/{id:int}, got %s", p)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// UUID
+// -----------------------------------------------------------------------------
+
+func TestParseUUID(t *testing.T) {
+	s := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	u, err := ParseUUID(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u[0] != 0x6b || u[15] != 0xc8 {
+		t.Errorf("unexpected bytes: %x", u)
+	}
+	if u.String() != s {
+		t.Errorf("expected %s, got %s", s, u.String())
+	}
+}
+
+func TestParseUUIDInvalid(t *testing.T) {
+	for _, s := range []string{"", "6ba7b810", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430cg"} {
+		if _, err := ParseUUID(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
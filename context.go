@@ -7,19 +7,58 @@ import (
 	"mime"
 	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type Context struct {
 	w *ResponseWriter
 	r *http.Request
 
+	// response writer embedded to avoid an allocation per request
+	rw ResponseWriter
+
+	// parsed query values, cached per request, and the map they are
+	// parsed into, kept across requests
+	query  url.Values
+	values url.Values
+
+	// group of the matched route
+	group *Group
+
+	// matched route
+	route *Route
+
+	// router serving the request
+	router *Router
+
+	// writer passed to the mux while matching
+	rsp responder
+
 	// request-scoped storage
 	locals []local
+
+	// locals shared with the request context.Context by ContextLocals
+	scope *localsScope
+
+	// logger attributes added with LogWith
+	logArgs []any
+
+	// logger attributes shared with the request context.Context by
+	// LogContext
+	logs *logScope
+
+	// debug mode: the stack that acquired the Context, and the tombstone
+	// left when it was released
+	stack []byte
+	tomb  *tombstone
 }
 
 type local struct {
 	key   string
+	id    *keyID
 	value any
 }
 
@@ -27,31 +66,96 @@ type local struct {
 
 // Method returns the HTTP method
 func (c *Context) Method() string {
+	c.checkReleased()
 	return c.r.Method
 }
 
 // Path returns the URL path
 func (c *Context) Path() string {
+	c.checkReleased()
 	return c.r.URL.Path
 }
 
 // Context returns the request context
 func (c *Context) Context() context.Context {
+	c.checkReleased()
 	return c.r.Context()
 }
 
+// Route metadata
+
+// RouteMeta returns a metadata value of the matched route, or nil
+func (c *Context) RouteMeta(key string) any {
+	c.checkReleased()
+	if c.route == nil {
+		return nil
+	}
+	return c.route.meta[key]
+}
+
+// RouteTags returns the tags of the matched route. The slice must not
+// be modified.
+func (c *Context) RouteTags() []string {
+	c.checkReleased()
+	if c.route == nil {
+		return nil
+	}
+	return c.route.tags
+}
+
 // Path parameters
 
-// Param returns a path parameter by name
+// Param returns a path or host parameter by name
 func (c *Context) Param(name string) string {
-	return c.r.PathValue(name)
+	c.checkReleased()
+	if v := c.r.PathValue(name); v != "" {
+		return v
+	}
+	if v := mountValue(c.r, name); v != "" || c.group == nil || c.group.host == nil {
+		return v
+	}
+	return c.group.host.param(c.r.Host, name)
+}
+
+// ParamInt parses a path parameter as int
+func (c *Context) ParamInt(name string, fallback ...int) int {
+	c.checkReleased()
+	v, err := strconv.Atoi(c.Param(name))
+	if err != nil && len(fallback) > 0 {
+		return fallback[0]
+	}
+	return v
+}
+
+// ParamInt64 parses a path parameter as int64
+func (c *Context) ParamInt64(name string, fallback ...int64) int64 {
+	c.checkReleased()
+	v, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil && len(fallback) > 0 {
+		return fallback[0]
+	}
+	return v
+}
+
+// ParamUUID parses a path parameter as UUID
+func (c *Context) ParamUUID(name string, fallback ...UUID) UUID {
+	c.checkReleased()
+	v, err := ParseUUID(c.Param(name))
+	if err != nil && len(fallback) > 0 {
+		return fallback[0]
+	}
+	return v
 }
 
 // Query parameters
 
 // Query returns a query parameter by name
 func (c *Context) Query(key string, fallback ...string) string {
-	v := c.r.URL.Query().Get(key)
+	c.checkReleased()
+	var v string
+	if c.r.URL.RawQuery != "" {
+		v = c.queries().Get(key)
+	}
 	if v == "" && len(fallback) > 0 {
 		return fallback[0]
 	}
@@ -60,6 +164,7 @@ func (c *Context) Query(key string, fallback ...string) string {
 
 // QueryInt parses a query parameter as int
 func (c *Context) QueryInt(key string, fallback ...int) int {
+	c.checkReleased()
 	v, err := strconv.Atoi(c.Query(key))
 	if err != nil && len(fallback) > 0 {
 		return fallback[0]
@@ -67,20 +172,78 @@ func (c *Context) QueryInt(key string, fallback ...int) int {
 	return v
 }
 
-// Queries returns all query parameters
+// Queries returns a copy of all query parameters, which the caller may
+// keep and modify
 func (c *Context) Queries() url.Values {
-	return c.r.URL.Query()
+	c.checkReleased()
+	q := make(url.Values, len(c.queries()))
+	for k, v := range c.query {
+		q[k] = slices.Clone(v)
+	}
+	return q
+}
+
+// queries returns the query parameters, parsed once per request into a
+// map the Context reuses: it must not be kept or modified
+func (c *Context) queries() url.Values {
+	if c.query == nil {
+		if c.values == nil {
+			c.values = make(url.Values)
+		}
+		parseQuery(c.values, c.r.URL.RawQuery)
+		c.query = c.values
+	}
+	return c.query
+}
+
+// parseQuery parses query into m like url.ParseQuery, reusing the keys
+// and value slices of m
+func parseQuery(m url.Values, query string) {
+	for k, v := range m {
+		clear(v)
+		m[k] = v[:0]
+	}
+	for query != "" {
+		var key string
+		key, query, _ = strings.Cut(query, "&")
+		if key == "" || strings.Contains(key, ";") {
+			continue
+		}
+		key, value, _ := strings.Cut(key, "=")
+		key, err1 := queryUnescape(key)
+		value, err2 := queryUnescape(value)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		m[key] = append(m[key], value)
+	}
+	for k, v := range m {
+		if len(v) == 0 {
+			delete(m, k)
+		}
+	}
+}
+
+// queryUnescape unescapes a query component, without allocating when
+// it has no escapes
+func queryUnescape(s string) (string, error) {
+	if !strings.ContainsAny(s, "%+") {
+		return s, nil
+	}
+	return url.QueryUnescape(s)
 }
 
 // Headers
 
 // Header returns a request header by key
 func (c *Context) Header(key string) string {
+	c.checkReleased()
 	return c.r.Header.Get(key)
 }
 
 // SetHeader sets a response header
 func (c *Context) SetHeader(key, value string) {
+	c.checkReleased()
 	c.w.Header().Set(key, value)
 }
 
@@ -88,6 +251,7 @@ func (c *Context) SetHeader(key, value string) {
 
 // Cookie returns a request cookie value by name
 func (c *Context) Cookie(name string, fallback ...string) string {
+	c.checkReleased()
 	cookie, err := c.r.Cookie(name)
 	if err != nil && len(fallback) > 0 {
 		return fallback[0]
@@ -100,6 +264,7 @@ func (c *Context) Cookie(name string, fallback ...string) string {
 
 // SetCookie adds a cookie to the response
 func (c *Context) SetCookie(cookie *http.Cookie) {
+	c.checkReleased()
 	http.SetCookie(c.w, cookie)
 }
 
@@ -107,37 +272,76 @@ func (c *Context) SetCookie(cookie *http.Cookie) {
 
 // Set stores a value in locals
 func (c *Context) Set(key string, value any) {
-	for i := range c.locals {
-		if c.locals[i].key == key {
-			c.locals[i].value = value
+	c.checkReleased()
+	c.store(key, nil, value)
+}
+
+// Get retrieves a value from locals
+func (c *Context) Get(key string) any {
+	c.checkReleased()
+	v, _ := c.load(key, nil)
+	return v
+}
+
+// store sets the local of the string key, or of the typed key id
+func (c *Context) store(key string, id *keyID, value any) {
+	if c.logs != nil && id == RequestIDKey.id {
+		// the request ID is a logger attribute
+		defer c.syncLog()
+	}
+	if c.scope != nil {
+		c.scope.store(key, id, value)
+		return
+	}
+	setLocal(&c.locals, key, id, value)
+}
+
+// setLocal sets the local of the string key, or of the typed key id, in
+// locals
+func setLocal(locals *[]local, key string, id *keyID, value any) {
+	for i := range *locals {
+		if (*locals)[i].key == key && (*locals)[i].id == id {
+			(*locals)[i].value = value
 			return
 		}
 	}
 	if value == nil {
 		return
 	}
-	n := len(c.locals)
-	if cap(c.locals) > n {
-		c.locals = c.locals[:n+1]
-		c.locals[n].key = key
-		c.locals[n].value = value
+	n := len(*locals)
+	if cap(*locals) > n {
+		*locals = (*locals)[:n+1]
+		(*locals)[n] = local{key, id, value}
 		return
 	}
-	c.locals = append(c.locals, local{key, value})
+	*locals = append(*locals, local{key, id, value})
 }
 
-// Get retrieves a value from locals
-func (c *Context) Get(key string) any {
-	for i := range c.locals {
-		if c.locals[i].key == key {
-			return c.locals[i].value
+// load returns the local of the string key, or of the typed key id
+func (c *Context) load(key string, id *keyID) (any, bool) {
+	return loadLocal(c.localSlice(), key, id)
+}
+
+// localSlice returns the locals, which must not be modified
+func (c *Context) localSlice() []local {
+	if c.scope != nil {
+		return c.scope.load()
+	}
+	return c.locals
+}
+
+func loadLocal(locals []local, key string, id *keyID) (any, bool) {
+	for i := range locals {
+		if locals[i].key == key && locals[i].id == id {
+			return locals[i].value, true
 		}
 	}
-	return nil
+	return nil, false
 }
 
 // GetString retrieves a string from locals
 func (c *Context) GetString(key string) string {
+	c.checkReleased()
 	if v, ok := c.Get(key).(string); ok {
 		return v
 	}
@@ -146,6 +350,7 @@ func (c *Context) GetString(key string) string {
 
 // GetInt retrieves an int from locals
 func (c *Context) GetInt(key string) int {
+	c.checkReleased()
 	if v, ok := c.Get(key).(int); ok {
 		return v
 	}
@@ -154,6 +359,7 @@ func (c *Context) GetInt(key string) int {
 
 // GetBool retrieves a bool from locals
 func (c *Context) GetBool(key string) bool {
+	c.checkReleased()
 	if v, ok := c.Get(key).(bool); ok {
 		return v
 	}
@@ -164,11 +370,13 @@ func (c *Context) GetBool(key string) bool {
 
 // Body returns the raw request body
 func (c *Context) Body() ([]byte, error) {
+	c.checkReleased()
 	return io.ReadAll(c.r.Body)
 }
 
 // Bind decodes request body into v with auto-detect content type
 func (c *Context) Bind(v any) error {
+	c.checkReleased()
 	switch c.ContentType() {
 	case MIMEApplicationXML, MIMETextXML:
 		// TODO: implement decodeXML(c, v)
@@ -181,13 +389,39 @@ func (c *Context) Bind(v any) error {
 	}
 }
 
+// BindRequest binds the body, path parameters, query and headers of the
+// request into the struct v and validates it. Fields tagged path, query
+// or header are bound from those; a request body is bound like Bind.
+// Bind errors are 400 HTTPErrors, and invalid values are reported in a
+// *ValidationError.
+func (c *Context) BindRequest(v any) error {
+	c.checkReleased()
+	if c.r.ContentLength != 0 && c.r.Body != nil && c.r.Body != http.NoBody {
+		if err := c.Bind(v); err != nil {
+			return &HTTPError{Status: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
+
+	var errs []Violation
+	bindParams(c, reflect.ValueOf(v), &errs)
+	if len(errs) == 0 {
+		validateStruct(reflect.ValueOf(v), "", &errs)
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Violations: errs}
+	}
+	return nil
+}
+
 // FormValue returns a form field by name
 func (c *Context) FormValue(name string) string {
+	c.checkReleased()
 	return c.r.FormValue(name)
 }
 
 // ContentType returns the parsed media type from Content-Type header
 func (c *Context) ContentType() string {
+	c.checkReleased()
 	ct, _, _ := mime.ParseMediaType(c.r.Header.Get("Content-Type"))
 	return ct
 }
@@ -196,11 +430,13 @@ func (c *Context) ContentType() string {
 
 // Status writes a status code with empty body
 func (c *Context) Status(code int) error {
+	c.checkReleased()
 	return c.Blob(code, "", nil)
 }
 
 // NoContent writes 204 No Content
 func (c *Context) NoContent() error {
+	c.checkReleased()
 	return c.Blob(http.StatusNoContent, "", nil)
 }
 
@@ -208,6 +444,7 @@ func (c *Context) NoContent() error {
 
 // JSON writes a JSON response
 func (c *Context) JSON(status int, v any) error {
+	c.checkReleased()
 	data, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -217,60 +454,99 @@ func (c *Context) JSON(status int, v any) error {
 
 // OK writes 200 JSON response
 func (c *Context) OK(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusOK, v)
 }
 
 // Created writes 201 JSON response
 func (c *Context) Created(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusCreated, v)
 }
 
 // BadRequest writes 400 JSON response
 func (c *Context) BadRequest(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusBadRequest, v)
 }
 
 // Unauthorized writes 401 JSON response
 func (c *Context) Unauthorized(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusUnauthorized, v)
 }
 
 // Forbidden writes 403 JSON response
 func (c *Context) Forbidden(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusForbidden, v)
 }
 
 // NotFound writes 404 JSON response
 func (c *Context) NotFound(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusNotFound, v)
 }
 
 // MethodNotAllowed writes 405 JSON response
 func (c *Context) MethodNotAllowed(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusMethodNotAllowed, v)
 }
 
 // InternalServerError writes 500 JSON response
 func (c *Context) InternalServerError(v any) error {
+	c.checkReleased()
 	return c.JSON(http.StatusInternalServerError, v)
 }
 
+// Problem writes an RFC 9457 problem details response. The title
+// defaults to the status text.
+func (c *Context) Problem(p *Problem) error {
+	c.checkReleased()
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Blob(p.Status, MIMEApplicationProblemJSON, data)
+}
+
 // Response
 
 // String writes a plain text response
 func (c *Context) String(status int, s string) error {
-	return c.Blob(status, MIMETextPlain, []byte(s))
+	c.checkReleased()
+	c.setContentType(MIMETextPlain)
+	c.w.WriteHeader(status)
+
+	if len(s) > 0 {
+		_, err := c.w.WriteString(s)
+		return err
+	}
+	return nil
 }
 
 // HTML writes an HTML response
 func (c *Context) HTML(status int, html string) error {
-	return c.Blob(status, MIMETextHTML, []byte(html))
+	c.checkReleased()
+	c.setContentType(MIMETextHTML)
+	c.w.WriteHeader(status)
+
+	if len(html) > 0 {
+		_, err := c.w.WriteString(html)
+		return err
+	}
+	return nil
 }
 
 // Blob writes raw bytes with content type
 func (c *Context) Blob(status int, contentType string, data []byte) error {
+	c.checkReleased()
 	if contentType != "" {
-		c.w.Header().Set("Content-Type", contentType)
+		c.setContentType(contentType)
 	}
 
 	c.w.WriteHeader(status)
@@ -282,14 +558,41 @@ func (c *Context) Blob(status int, contentType string, data []byte) error {
 	return nil
 }
 
+// setContentType sets the Content-Type response header. A header a
+// writer reuses across responses keeps its value without allocating.
+func (c *Context) setContentType(contentType string) {
+	h := c.w.Header()
+	if v := h["Content-Type"]; len(v) == 1 && v[0] == contentType {
+		return
+	}
+	h.Set("Content-Type", contentType)
+}
+
 func (c *Context) attach(w http.ResponseWriter, r *http.Request) {
-	c.w = &ResponseWriter{ResponseWriter: w}
+	c.rw = ResponseWriter{ResponseWriter: w}
+	c.w = &c.rw
 	c.r = r
 }
 
 func (c *Context) detach() {
 	c.w = nil
 	c.r = nil
+	c.rw = ResponseWriter{}
+	c.query = nil
+	if len(c.values) > 64 {
+		// do not keep a large map in the pool
+		c.values = nil
+	}
+	c.group = nil
+	c.route = nil
+	c.router = nil
+	if c.scope != nil {
+		c.scope.c.Store(nil)
+		c.scope = nil
+	}
 	clear(c.locals)
 	c.locals = c.locals[:0]
+	clear(c.logArgs)
+	c.logArgs = c.logArgs[:0]
+	c.logs = nil
 }
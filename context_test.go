@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -61,6 +63,78 @@ func TestContextContext(t *testing.T) {
 	r.ServeHTTP(rec, req)
 }
 
+// -----------------------------------------------------------------------------
+// Path Parameters
+// -----------------------------------------------------------------------------
+
+func TestContextParamInt(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.ParamInt("id"), "id64": c.ParamInt64("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["id"] != float64(42) || body["id64"] != float64(42) {
+		t.Errorf("expected 42, got %v", body)
+	}
+}
+
+func TestContextParamIntFallback(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.ParamInt("id", -1), "id64": c.ParamInt64("id", -1)})
+	})
+
+	req := httptest.NewRequest("GET", "/users/abc", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["id"] != float64(-1) || body["id64"] != float64(-1) {
+		t.Errorf("expected -1, got %v", body)
+	}
+}
+
+func TestContextParamUUID(t *testing.T) {
+	r := New()
+	r.GET("/orders/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.ParamUUID("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/orders/6ba7b810-9dad-11d1-80b4-00c04fd430c8", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["id"] != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("expected uuid, got %v", body["id"])
+	}
+}
+
+func TestContextParamUUIDFallback(t *testing.T) {
+	fallback := UUID{1}
+	r := New()
+	r.GET("/orders/{id}", func(c *Context) error {
+		if c.ParamUUID("id", fallback) != fallback || c.ParamUUID("id") != (UUID{}) {
+			return c.Status(500)
+		}
+		return c.NoContent()
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/orders/abc", nil))
+	if rec.Code != 204 {
+		t.Errorf("expected fallback for invalid UUID, got %d", rec.Code)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Query Parameters
 // -----------------------------------------------------------------------------
@@ -168,6 +242,43 @@ func TestContextQueries(t *testing.T) {
 	}
 }
 
+func TestContextQueriesCopy(t *testing.T) {
+	var kept []url.Values
+	r := New()
+	r.GET("/", func(c *Context) error {
+		q := c.Queries()
+		kept = append(kept, q)
+		q.Set("seen", "yes")
+		return c.OK(M{"name": c.Query("name"), "seen": c.Query("seen")})
+	})
+
+	for _, name := range []string{"john", "jane"} {
+		req := httptest.NewRequest("GET", "/?name="+name, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if body["name"] != name || body["seen"] != "" {
+			t.Errorf("expected %s and no changes from the copy, got %v", name, body)
+		}
+	}
+	if kept[0].Get("name") != "john" {
+		t.Errorf("expected kept queries to survive the next request, got %v", kept[0])
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	m := url.Values{"stale": {"x"}, "a": {"old", "older"}}
+	for _, query := range []string{"a=1&a=2&b=%20x+y", "&=v&k&c=%zz&d=1;2&e=%41", ""} {
+		parseQuery(m, query)
+		want, _ := url.ParseQuery(query)
+		if !reflect.DeepEqual(m, want) {
+			t.Errorf("%q: expected %v, got %v", query, want, m)
+		}
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Headers
 // -----------------------------------------------------------------------------
@@ -190,6 +301,23 @@ func TestContextHeader(t *testing.T) {
 	}
 }
 
+func TestContextContentTypeNotShared(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) error {
+		return c.String(200, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	rec.Header()["Content-Type"][0] = "application/octet-stream"
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != MIMETextPlain {
+		t.Errorf("expected %s, got %s", MIMETextPlain, ct)
+	}
+}
+
 func TestContextSetHeader(t *testing.T) {
 	r := New()
 	r.GET("/", func(c *Context) error {
@@ -775,3 +903,121 @@ func TestContextDetach(t *testing.T) {
 		t.Errorf("expected empty locals, got %d", len(c.locals))
 	}
 }
+
+func TestContextDetachResponse(t *testing.T) {
+	c := &Context{}
+	c.attach(httptest.NewRecorder(), httptest.NewRequest("GET", "/?q=1", nil))
+	c.Queries()
+	c.String(200, "ok")
+
+	c.detach()
+
+	if c.w != nil || c.rw.ResponseWriter != nil || c.rw.Status() != 0 || c.rw.Size() != 0 {
+		t.Error("expected response writer to be reset after detach")
+	}
+	if c.query != nil {
+		t.Error("expected query cache to be reset after detach")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Route Metadata
+// -----------------------------------------------------------------------------
+
+func requirePermission(next Handler) Handler {
+	return func(c *Context) error {
+		perm, _ := c.RouteMeta("permission").(string)
+		if perm != "" && c.Header("X-Permission") != perm {
+			return c.Forbidden(M{"error": "forbidden"})
+		}
+		return next(c)
+	}
+}
+
+func TestContextRouteMetaInMiddleware(t *testing.T) {
+	r := New()
+	r.Use(requirePermission)
+	r.GET("/admin", func(c *Context) error { return c.OK(nil) }).Meta("permission", "admin")
+	r.GET("/public", func(c *Context) error { return c.OK(nil) })
+
+	tests := []struct {
+		path, perm string
+		code       int
+	}{
+		{"/admin", "", 403},
+		{"/admin", "admin", 200},
+		{"/public", "", 200},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("X-Permission", tt.perm)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s with %q: expected %d, got %d", tt.path, tt.perm, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestContextRouteTags(t *testing.T) {
+	r := New()
+	var tags []string
+	r.GET("/users", func(c *Context) error {
+		tags = c.RouteTags()
+		return c.OK(nil)
+	}).Tags("users", "public")
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(tags) != 2 || tags[0] != "users" || tags[1] != "public" {
+		t.Errorf("expected route tags, got %v", tags)
+	}
+	if info := r.Routes()[0]; len(info.Tags) != 2 {
+		t.Errorf("expected tags in route info, got %v", info.Tags)
+	}
+}
+
+func TestContextRouteMetaVersioned(t *testing.T) {
+	r := New(Config{Versioning: VersionConfig{Strategy: VersionHeader}})
+	var tier any
+	mw := func(next Handler) Handler {
+		return func(c *Context) error {
+			tier = c.RouteMeta("tier")
+			return next(c)
+		}
+	}
+	r.Version("1", func(g *Group) {
+		g.Use(mw)
+		g.GET("/items", listUsers).Meta("tier", "free")
+	})
+	r.Version("2", func(g *Group) {
+		g.Use(mw)
+		g.GET("/items", listUsers).Meta("tier", "paid")
+	})
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("API-Version", "2")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if tier != "paid" {
+		t.Errorf("expected metadata of the selected version, got %v", tier)
+	}
+}
+
+func TestContextRouteMetaUnmatched(t *testing.T) {
+	r := New()
+	var meta any = "unset"
+	r.On404(func(c *Context) error {
+		meta = c.RouteMeta("permission")
+		return c.NotFound(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if meta != nil {
+		t.Errorf("expected nil metadata without a route, got %v", meta)
+	}
+}
@@ -0,0 +1,107 @@
+package mux
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+)
+
+// contextKey is the context.Context key of the Context
+type contextKey struct{}
+
+// localsKey is implemented by Key for lookups through context.Context
+type localsKey interface {
+	keyID() *keyID
+}
+
+// localsScope holds the locals of a request when they are mirrored into
+// its context.Context. It is not pooled, so a context.Context that
+// outlives the request keeps reading the values of its own request.
+type localsScope struct {
+	// c is the Context while the handler runs, and nil once it returned
+	// and the Context went back to the pool
+	c atomic.Pointer[Context]
+
+	// locals are replaced rather than modified, so that the request
+	// context reads them while the handler sets others
+	locals atomic.Pointer[[]local]
+}
+
+// load returns the locals of the scope
+func (s *localsScope) load() []local {
+	if p := s.locals.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// store sets a local in a copy of the locals of the scope
+func (s *localsScope) store(key string, id *keyID, value any) {
+	locals := slices.Clone(s.load())
+	setLocal(&locals, key, id, value)
+	s.locals.Store(&locals)
+}
+
+// localsContext is a request context.Context that reads locals
+type localsContext struct {
+	context.Context
+	scope *localsScope
+}
+
+func (ctx *localsContext) Value(key any) any {
+	// mux keys are answered by the innermost localsContext: a copy holds
+	// all locals of its request, and the request context has no others
+	switch k := key.(type) {
+	case contextKey:
+		if c := ctx.scope.c.Load(); c != nil {
+			return c
+		}
+		return nil
+	case localsKey:
+		if id := k.keyID(); id != nil {
+			v, _ := loadLocal(ctx.scope.load(), id.name, id)
+			return v
+		}
+		return nil
+	case string:
+		if v, ok := loadLocal(ctx.scope.load(), k, nil); ok {
+			return v
+		}
+	}
+	return ctx.Context.Value(key)
+}
+
+// mirror moves the locals of c into a scope the request context reads
+func (c *Context) mirror() {
+	c.scope = &localsScope{}
+	locals := c.locals
+	c.scope.locals.Store(&locals)
+	c.scope.c.Store(c)
+	c.locals = nil
+	c.r = c.r.WithContext(&localsContext{Context: c.r.Context(), scope: c.scope})
+}
+
+// SetContext replaces the request context, e.g. with a deadline or a
+// tracing span. Derive ctx from Context to keep its values.
+func (c *Context) SetContext(ctx context.Context) {
+	c.checkReleased()
+	c.r = c.r.WithContext(ctx)
+}
+
+// WithValue replaces the request context with one that carries the value
+func (c *Context) WithValue(key, value any) {
+	c.checkReleased()
+	c.SetContext(context.WithValue(c.r.Context(), key, value))
+}
+
+// FromContext returns the Context of the request ctx belongs to, or nil
+// when the handler returned or the router does not set ContextLocals.
+// The Context returned by Copy, whose context Go passes, is found too.
+//
+// The Context of a request is reused once its handler returns, so only
+// call FromContext from the handler's goroutine. Goroutines that outlive
+// the handler must use the context of Context.Copy or Context.Go.
+func FromContext(ctx context.Context) *Context {
+	c, _ := ctx.Value(contextKey{}).(*Context)
+	return c
+}
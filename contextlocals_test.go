@@ -0,0 +1,177 @@
+package mux
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+// service reads request values the way code below the handler does
+func service(ctx context.Context) M {
+	m := M{"tenant": ctx.Value("tenant"), "trace": ctx.Value(traceKey{})}
+	if a, ok := ctx.Value(accountKey).(*account); ok {
+		m["user"] = a.Name
+	}
+	if c := FromContext(ctx); c != nil {
+		m["path"] = c.Path()
+	}
+	if _, ok := ctx.Deadline(); ok {
+		m["deadline"] = true
+	}
+	return m
+}
+
+// -----------------------------------------------------------------------------
+// Context Locals
+// -----------------------------------------------------------------------------
+
+func TestContextLocals(t *testing.T) {
+	r := New(Config{ContextLocals: true})
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			Store(c, accountKey, &account{Name: "bob"})
+			c.Set("tenant", "acme")
+			return next(c)
+		}
+	})
+
+	var got M
+	r.GET("/users", func(c *Context) error {
+		ctx, cancel := context.WithTimeout(c.Context(), time.Minute)
+		defer cancel()
+		c.SetContext(ctx)
+		c.WithValue(traceKey{}, "span-1")
+
+		got = service(c.Context())
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+
+	want := M{"tenant": "acme", "trace": "span-1", "user": "bob", "path": "/users", "deadline": true}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got[k])
+		}
+	}
+}
+
+func TestContextLocalsDisabled(t *testing.T) {
+	r := New()
+
+	var got M
+	r.GET("/", func(c *Context) error {
+		c.Set("tenant", "acme")
+		c.WithValue(traceKey{}, "span-1")
+		got = service(c.Context())
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if got["tenant"] != nil || got["path"] != nil || got["trace"] != "span-1" {
+		t.Errorf("expected only context values without ContextLocals, got %v", got)
+	}
+}
+
+func TestContextLocalsAfterRelease(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		r := New(Config{ContextLocals: true, Debug: debug})
+
+		var leaked context.Context
+		r.GET("/", func(c *Context) error {
+			c.Set("tenant", "acme")
+			leaked = c.Context()
+			return nil
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		// the next request reuses the Context unless in debug mode
+		r.GET("/other", func(c *Context) error {
+			c.Set("tenant", "other")
+			return nil
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+		if FromContext(leaked) != nil {
+			t.Errorf("debug=%v: expected no Context after release", debug)
+		}
+		if v := leaked.Value("tenant"); v != "acme" {
+			t.Errorf("debug=%v: expected the values of the released request, got %v", debug, v)
+		}
+	}
+}
+
+// TestFromContextRelease looks up the Context from another goroutine
+// while the handler returns; run with -race
+func TestFromContextRelease(t *testing.T) {
+	r := New(Config{ContextLocals: true})
+	ctxs := make(chan context.Context, 1)
+	r.GET("/", func(c *Context) error {
+		ctxs <- c.Context()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx := range ctxs {
+			for i := 0; i < 100; i++ {
+				FromContext(ctx)
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	close(ctxs)
+	<-done
+}
+
+func TestContextLocalsConcurrentValue(t *testing.T) {
+	r := New(Config{ContextLocals: true})
+	r.GET("/", func(c *Context) error {
+		ctx := c.Context()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				ctx.Value("k0")
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			c.Set("k"+strconv.Itoa(i), i)
+		}
+		<-done
+		return c.String(200, strconv.Itoa(ctx.Value("k99").(int)))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Body.String() != "99" {
+		t.Errorf("expected the last local, got %q", rec.Body.String())
+	}
+}
+
+func TestContextLocalsGo(t *testing.T) {
+	r := New(Config{ContextLocals: true})
+
+	done := make(chan M, 1)
+	r.GET("/jobs", func(c *Context) error {
+		c.Set("tenant", "acme")
+		c.Go(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			done <- service(ctx)
+			return nil
+		})
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/jobs", nil))
+
+	got := <-done
+	if got["tenant"] != "acme" || got["path"] != "/jobs" {
+		t.Errorf("expected the copy's locals in Go tasks, got %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package mux
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"slices"
+)
+
+// Copy returns a snapshot of the Context that stays valid after the
+// handler returns, for use in background goroutines. The copy keeps the
+// method, path, params, request headers and locals, including a request
+// ID set by RequestID, and its Context is not canceled with the request.
+// The request body is not available and responses written to the copy
+// are discarded.
+func (c *Context) Copy() *Context {
+	c.checkReleased()
+	r := c.r.Clone(context.WithoutCancel(c.r.Context()))
+	r.Body = http.NoBody
+	r.GetBody = nil
+
+	cc := &Context{
+		r:      r,
+		group:  c.group,
+		route:  c.route,
+		router: c.router,
+		locals: slices.Clone(c.localSlice()),
+
+		logArgs: slices.Clone(c.logArgs),
+	}
+	cc.rw = ResponseWriter{ResponseWriter: &discardWriter{header: make(http.Header)}}
+	cc.w = &cc.rw
+	if c.router != nil && c.router.cfg.ContextLocals {
+		cc.mirror()
+	}
+	if c.logs != nil {
+		cc.attachLog()
+	}
+	return cc
+}
+
+// Go runs fn in a goroutine with the Context of a Copy, which outlives
+// the request. Errors returned by fn and panics go to the router's
+// OnGoErr handler, which logs them by default.
+func (c *Context) Go(fn func(ctx context.Context) error) {
+	c.checkReleased()
+	cc := c.Copy()
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				cc.goErr(fmt.Errorf("panic: %v\n%s", e, debug.Stack()))
+			}
+		}()
+		if err := fn(cc.Context()); err != nil {
+			cc.goErr(err)
+		}
+	}()
+}
+
+// goErr reports an error of a Go task
+func (c *Context) goErr(err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			log.Printf("mux: panic in background error handler: %v", e)
+		}
+	}()
+	if c.router == nil || c.router.onGoErr == nil {
+		log.Printf("mux: background task of %s %s: %v", c.Method(), c.Path(), err)
+		return
+	}
+	c.router.onGoErr(c, err)
+}
+
+// discardWriter is the response writer of a Context copy
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
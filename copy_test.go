@@ -0,0 +1,114 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Copy
+// -----------------------------------------------------------------------------
+
+func TestContextCopy(t *testing.T) {
+	r := New()
+	r.Use(RequestID())
+
+	copies := make(chan *Context, 1)
+	r.Host("{tenant}.example.com").GET("/users/{id}", func(c *Context) error {
+		c.Set("user", "bob")
+		copies <- c.Copy()
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "http://acme.example.com/users/7?sort=asc", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	cc := <-copies
+	if cc.Method() != "GET" || cc.Path() != "/users/7" {
+		t.Errorf("expected request line, got %s %s", cc.Method(), cc.Path())
+	}
+	if cc.Param("id") != "7" || cc.Param("tenant") != "acme" || cc.Query("sort") != "asc" {
+		t.Errorf("expected params, got id=%q tenant=%q sort=%q", cc.Param("id"), cc.Param("tenant"), cc.Query("sort"))
+	}
+	if cc.Header("X-Request-ID") != "req-1" || cc.GetString("X-Request-ID") != "req-1" || cc.GetString("user") != "bob" {
+		t.Errorf("expected headers and locals, got %+v", cc.locals)
+	}
+	if cc.Context().Err() != nil {
+		t.Errorf("expected the copy context to outlive the request, got %v", cc.Context().Err())
+	}
+	if err := cc.OK(M{"late": true}); err != nil {
+		t.Errorf("expected writes to a copy to be discarded, got %v", err)
+	}
+}
+
+func TestContextCopyCancel(t *testing.T) {
+	r := New()
+
+	copies := make(chan *Context, 1)
+	r.GET("/", func(c *Context) error {
+		copies <- c.Copy()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	cancel()
+
+	if err := (<-copies).Context().Err(); err != nil {
+		t.Errorf("expected copy not canceled with the request, got %v", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Go
+// -----------------------------------------------------------------------------
+
+func TestContextGo(t *testing.T) {
+	r := New()
+
+	errs := make(chan string, 2)
+	r.OnGoErr(func(c *Context, err error) {
+		errs <- c.Path() + ": " + strings.SplitN(err.Error(), "\n", 2)[0]
+	})
+
+	done := make(chan string, 1)
+	r.GET("/ok", func(c *Context) error {
+		c.Set("user", "bob")
+		c.Go(func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			done <- "done"
+			return ctx.Err()
+		})
+		return c.NoContent()
+	})
+	r.GET("/fail", func(c *Context) error {
+		c.Go(func(ctx context.Context) error { return errors.New("boom") })
+		return nil
+	})
+	r.GET("/panic", func(c *Context) error {
+		c.Go(func(ctx context.Context) error { panic("oops") })
+		return nil
+	})
+
+	for _, path := range []string{"/ok", "/fail", "/panic"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if <-done != "done" {
+		t.Error("expected task to run")
+	}
+	got := map[string]bool{<-errs: true, <-errs: true}
+	if !got["/fail: boom"] || !got["/panic: panic: oops"] {
+		t.Errorf("expected reported error and panic, got %v", got)
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected error %s", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,38 @@
+package mux
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// tombstone replaces the state of a Context released in debug mode
+type tombstone struct {
+	method, path string
+	stack        []byte
+}
+
+// acquire records the stack that acquired the Context in debug mode
+func (c *Context) acquire() {
+	c.stack = debug.Stack()
+}
+
+// poison detaches the Context and leaves a tombstone, so later method
+// calls panic instead of reading the state of another request
+func (c *Context) poison() {
+	t := &tombstone{stack: c.stack}
+	if c.r != nil {
+		t.method, t.path = c.r.Method, c.r.URL.Path
+	}
+	c.detach()
+	c.stack = nil
+	c.tomb = t
+}
+
+// checkReleased panics when the Context was released in debug mode
+func (c *Context) checkReleased() {
+	if c.tomb != nil {
+		panic(fmt.Sprintf("mux: Context of %s %s used after the handler returned; "+
+			"use Context.Copy or Context.Go for work that outlives the request\n\n"+
+			"Context acquired at:\n%s", c.tomb.method, c.tomb.path, c.tomb.stack))
+	}
+}
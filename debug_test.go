@@ -0,0 +1,81 @@
+package mux
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recovered calls fn and returns the value it panicked with
+func recovered(fn func()) (v any) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Debug Mode
+// -----------------------------------------------------------------------------
+
+func TestDebugPoisonsReleasedContext(t *testing.T) {
+	r := New(Config{Debug: true})
+
+	var retained, copied *Context
+	r.GET("/users/{id}", func(c *Context) error {
+		retained, copied = c, c.Copy()
+		return c.OK(M{"id": c.Param("id")})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/users/7", nil))
+	if rec.Code != 200 || rec.Body.String() != `{"id":"7"}` {
+		t.Fatalf("expected normal response in debug mode, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	msg, _ := recovered(func() { retained.Param("id") }).(string)
+	if !strings.Contains(msg, "mux: Context of GET /users/7 used after the handler returned") {
+		t.Errorf("expected use-after-release panic, got %q", msg)
+	}
+	if !strings.Contains(msg, "TestDebugPoisonsReleasedContext") {
+		t.Errorf("expected acquiring stack in panic, got %q", msg)
+	}
+	if v := recovered(func() { _ = retained.JSON(200, nil) }); v == nil {
+		t.Error("expected writes to panic too")
+	}
+
+	if v := recovered(func() { copied.Param("id") }); v != nil || copied.Param("id") != "7" {
+		t.Errorf("expected copy to stay usable, got %v", v)
+	}
+}
+
+func TestDebugDoesNotReuseContexts(t *testing.T) {
+	r := New(Config{Debug: true})
+
+	var first *Context
+	r.GET("/", func(c *Context) error {
+		if c == first {
+			t.Error("expected released Context not to be reused")
+		}
+		first = c
+		return nil
+	})
+
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+}
+
+func TestDebugDisabled(t *testing.T) {
+	r := New()
+
+	var retained *Context
+	r.GET("/", func(c *Context) error {
+		retained = c
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if retained.tomb != nil || retained.stack != nil {
+		t.Error("expected no tombstone without debug mode")
+	}
+}
@@ -0,0 +1,101 @@
+package mux
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// deprecation marks a route as deprecated
+type deprecation struct {
+	since     time.Time
+	sunset    time.Time
+	successor string
+	calls     atomic.Int64
+
+	// unix nanoseconds of the last logged call
+	logged atomic.Int64
+
+	// header values
+	deprecationHeader string
+	sunsetHeader      string
+	linkHeader        string
+}
+
+// DeprecationInfo describes a deprecated route
+type DeprecationInfo struct {
+	Since     time.Time `json:"since"`
+	Sunset    time.Time `json:"sunset,omitzero"`
+	Successor string    `json:"successor,omitempty"`
+	Calls     int64     `json:"calls"`
+}
+
+// deprecationLogInterval is the minimum time between logged calls of a
+// deprecated route
+const deprecationLogInterval = time.Minute
+
+// Deprecated marks the route as deprecated since the date. Responses
+// carry Deprecation, Sunset and Link rel="successor-version" headers, and
+// calls are counted and logged at most once a minute with Context.Logger.
+// From the sunset date the route returns ErrSunset, a 410 Gone, to the
+// error handler. A zero sunset or empty successor omits the header.
+func (rt *Route) Deprecated(since, sunset time.Time, successor string) *Route {
+	if since.IsZero() {
+		panic("mux: Deprecated needs the date the route was deprecated")
+	}
+	d := &deprecation{
+		since:     since.Truncate(time.Second),
+		sunset:    sunset,
+		successor: successor,
+	}
+	d.deprecationHeader = "@" + strconv.FormatInt(d.since.Unix(), 10)
+	if !sunset.IsZero() {
+		d.sunsetHeader = sunset.UTC().Format(http.TimeFormat)
+	}
+	if successor != "" {
+		d.linkHeader = "<" + successor + `>; rel="successor-version"`
+	}
+	rt.deprecation = d
+	return rt
+}
+
+// serve sets the deprecation headers and calls next until the sunset
+func (d *deprecation) serve(c *Context, next Handler) error {
+	h := c.w.Header()
+	h.Set("Deprecation", d.deprecationHeader)
+	if d.sunsetHeader != "" {
+		h.Set("Sunset", d.sunsetHeader)
+	}
+	if d.linkHeader != "" {
+		h.Add("Link", d.linkHeader)
+	}
+
+	calls := d.calls.Add(1)
+	now := time.Now()
+	if last := d.logged.Load(); now.UnixNano()-last >= int64(deprecationLogInterval) && d.logged.CompareAndSwap(last, now.UnixNano()) {
+		c.Logger().Warn("deprecated route called",
+			"calls", calls,
+			"remote_addr", c.r.RemoteAddr,
+			"user_agent", c.Header("User-Agent"),
+		)
+	}
+
+	if !d.sunset.IsZero() && !now.Before(d.sunset) {
+		return ErrSunset
+	}
+	return next(c)
+}
+
+// info returns a snapshot of the deprecation
+func (d *deprecation) info() *DeprecationInfo {
+	if d == nil {
+		return nil
+	}
+	return &DeprecationInfo{
+		Since:     d.since,
+		Sunset:    d.sunset,
+		Successor: d.successor,
+		Calls:     d.calls.Load(),
+	}
+}
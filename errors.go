@@ -0,0 +1,71 @@
+package mux
+
+import "net/http"
+
+// HTTPError is an error with an HTTP status. The default error handler
+// responds with its status and message.
+type HTTPError struct {
+	Status  int
+	Message string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// ErrSunset is returned for deprecated routes past their sunset date.
+// It converts to a 410 Gone *HTTPError with errors.As.
+const ErrSunset = sunsetError("endpoint retired")
+
+// sunsetError is the type of ErrSunset
+type sunsetError string
+
+func (e sunsetError) Error() string {
+	return string(e)
+}
+
+func (e sunsetError) As(target any) bool {
+	if he, ok := target.(**HTTPError); ok {
+		*he = &HTTPError{Status: http.StatusGone, Message: string(e)}
+		return true
+	}
+	return false
+}
+
+// ErrDetachedContext is returned by a FromStd middleware when the std
+// middleware calls the next handler with a request context that is not
+// derived from the one it got, which hides the Context of the request
+const ErrDetachedContext = stdError("mux: std middleware detached the request context")
+
+// stdError is the type of ErrDetachedContext
+type stdError string
+
+func (e stdError) Error() string {
+	return string(e)
+}
+
+// Problem is an RFC 9457 problem details response
+type Problem struct {
+	Type     string      `json:"type,omitempty"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Errors   []Violation `json:"errors,omitempty"`
+}
+
+// Violation is a value of a request or response that does not match
+// its schema
+type Violation struct {
+	// In is path, query, header, cookie or body for requests, and
+	// response for responses
+	In string `json:"in"`
+
+	// Name of the parameter
+	Name string `json:"name,omitempty"`
+
+	// Pointer is the JSON pointer of the value in the body
+	Pointer string `json:"pointer,omitempty"`
+
+	Detail string `json:"detail"`
+}
@@ -1,10 +1,27 @@
 package mux
 
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
 // Group wraps Router with nested paths
 type Group struct {
 	prefix string
 	router *Router
+	parent *Group
+	host   *host
 	mws    []Middleware
+
+	// API version and the prefix with the version path segment
+	version string
+	vprefix string
+
+	// callbacks
+	on404 http.Handler
+	on405 http.Handler
+	onErr ErrorHandler
 }
 
 // Group creates a router group
@@ -19,44 +36,86 @@ func (r *Router) Group(prefix string) *Group {
 func (g *Group) Group(prefix string) *Group {
 	return &Group{
 		router: g.router,
+		parent: g,
+		host:   g.host,
 		prefix: g.prefix + prefix,
 		mws:    append([]Middleware{}, g.mws...),
+
+		version: g.version,
+		vprefix: g.vprefix + prefix,
 	}
 }
 
+// On404 sets the handler for 404 responses under the group prefix
+func (g *Group) On404(h Handler) {
+	g.on404 = g.router.handler(h, g, nil)
+	g.router.addScope(g)
+}
+
+// On405 sets the handler for 405 responses under the group prefix
+func (g *Group) On405(h Handler) {
+	g.on405 = g.router.handler(h, g, nil)
+	g.router.addScope(g)
+}
+
+// OnErr sets the error handler for the group and its nested groups
+func (g *Group) OnErr(h ErrorHandler) {
+	g.onErr = h
+}
+
 // Use adds middleware to the group
 func (g *Group) Use(middlewares ...Middleware) {
 	g.mws = append(g.mws, middlewares...)
 }
 
 // GET registers a handler for GET requests
-func (g *Group) GET(pattern string, h Handler) {
-	g.handle("GET", pattern, h)
+func (g *Group) GET(pattern string, h Handler) *Route {
+	return g.handle("GET", pattern, h)
 }
 
 // POST registers a handler for POST requests
-func (g *Group) POST(pattern string, h Handler) {
-	g.handle("POST", pattern, h)
+func (g *Group) POST(pattern string, h Handler) *Route {
+	return g.handle("POST", pattern, h)
 }
 
 // PUT registers a handler for PUT requests
-func (g *Group) PUT(pattern string, h Handler) {
-	g.handle("PUT", pattern, h)
+func (g *Group) PUT(pattern string, h Handler) *Route {
+	return g.handle("PUT", pattern, h)
 }
 
 // DELETE registers a handler for DELETE requests
-func (g *Group) DELETE(pattern string, h Handler) {
-	g.handle("DELETE", pattern, h)
+func (g *Group) DELETE(pattern string, h Handler) *Route {
+	return g.handle("DELETE", pattern, h)
 }
 
 // PATCH registers a handler for PATCH requests
-func (g *Group) PATCH(pattern string, h Handler) {
-	g.handle("PATCH", pattern, h)
+func (g *Group) PATCH(pattern string, h Handler) *Route {
+	return g.handle("PATCH", pattern, h)
+}
+
+// Mount serves h for all methods under prefix with the group prefix
+// and prefix stripped
+func (g *Group) Mount(prefix string, h http.Handler) *Route {
+	rt := g.handle("", mountPattern(prefix), mountHandler(g.prefix+prefix, h))
+	rt.handler = handlerName(h)
+	return rt
+}
+
+func (g *Group) handle(method, pattern string, h Handler) *Route {
+	return g.router.handle(g, method, g.prefix+pattern, h, g.mws...)
+}
+
+// addScope records a group with custom handlers
+func (r *Router) addScope(g *Group) {
+	if !slices.Contains(r.scopes, g) {
+		r.scopes = append(r.scopes, g)
+	}
 }
 
-func (g *Group) handle(method, pattern string, h Handler) {
-	for i := len(g.mws) - 1; i >= 0; i-- {
-		h = g.mws[i](h)
+// narrower reports whether g is more specific than other
+func (g *Group) narrower(other *Group) bool {
+	if (g.host != nil) != (other.host != nil) {
+		return g.host != nil
 	}
-	g.router.handle(method, g.prefix+pattern, h)
+	return strings.Count(g.prefix, "/") > strings.Count(other.prefix, "/")
 }
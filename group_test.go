@@ -2,6 +2,8 @@ package mux
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 )
@@ -357,6 +359,291 @@ func TestGroupMiddlewareShortCircuit(t *testing.T) {
 	}
 }
 
+// -----------------------------------------------------------------------------
+// Group Mount
+// -----------------------------------------------------------------------------
+
+func TestGroupMount(t *testing.T) {
+	r := New()
+	org := r.Group("/orgs/{org}")
+
+	var called bool
+	org.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			called = true
+			return next(c)
+		}
+	})
+	org.Mount("/std", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.PathValue("org") + " " + req.URL.Path))
+	}))
+
+	req := httptest.NewRequest("GET", "/orgs/acme/std/a/b", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if !called {
+		t.Error("expected group middleware to run for mounted handler")
+	}
+	if rec.Body.String() != "acme /a/b" {
+		t.Errorf("expected 'acme /a/b', got %s", rec.Body.String())
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Group 404
+// -----------------------------------------------------------------------------
+
+func TestGroupCustom404(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "api not found"})
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/missing", "api not found"},
+		{"/api", "api not found"},
+		{"/apix", "not found"},
+		{"/missing", "not found"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if rec.Code != 404 || body["error"] != tt.want {
+			t.Errorf("%s: expected %q, got %d %v", tt.path, tt.want, rec.Code, body)
+		}
+	}
+}
+
+func TestGroupNested404(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "api"})
+	})
+	v1 := api.Group("/v1")
+	v1.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "v1"})
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/missing", "v1"},
+		{"/api/v2/missing", "api"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if body["error"] != tt.want {
+			t.Errorf("%s: expected %s, got %v", tt.path, tt.want, body["error"])
+		}
+	}
+}
+
+func TestGroupWildcardPrefix404(t *testing.T) {
+	r := New()
+	org := r.Group("/orgs/{org}")
+	org.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "org"})
+	})
+
+	req := httptest.NewRequest("GET", "/orgs/acme/missing", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["error"] != "org" {
+		t.Errorf("expected org, got %v", body["error"])
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Group 405
+// -----------------------------------------------------------------------------
+
+func TestGroupCustom405(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.On405(func(c *Context) error {
+		return c.MethodNotAllowed(M{"error": "api method"})
+	})
+	api.GET("/users", func(c *Context) error { return c.OK(nil) })
+	r.GET("/pages", func(c *Context) error { return c.OK(nil) })
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/users", "api method"},
+		{"/pages", "method not allowed"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if rec.Code != 405 || body["error"] != tt.want {
+			t.Errorf("%s: expected %q, got %d %v", tt.path, tt.want, rec.Code, body)
+		}
+	}
+}
+
+func TestGroup404DoesNotHandle405(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "api"})
+	})
+	api.GET("/users", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("POST", "/api/users", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 405 {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Group Error Handler
+// -----------------------------------------------------------------------------
+
+func TestGroupErrorHandler(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.OnErr(func(c *Context, err error) {
+		_ = c.BadRequest(M{"api": err.Error()})
+	})
+	api.GET("/fail", func(c *Context) error {
+		return errors.New("boom")
+	})
+	r.GET("/fail", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/api/fail", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Errorf("expected group error handler 400, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/fail", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 500 {
+		t.Errorf("expected router error handler 500, got %d", rec.Code)
+	}
+}
+
+func TestGroupErrorHandlerNearest(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.OnErr(func(c *Context, err error) {
+		_ = c.JSON(500, M{"handler": "api"})
+	})
+	v1 := api.Group("/v1")
+	v1.GET("/inherit", func(c *Context) error {
+		return errors.New("boom")
+	})
+	v2 := api.Group("/v2")
+	v2.OnErr(func(c *Context, err error) {
+		_ = c.JSON(500, M{"handler": "v2"})
+	})
+	v2.GET("/own", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/inherit", "api"},
+		{"/api/v2/own", "v2"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if body["handler"] != tt.want {
+			t.Errorf("%s: expected %s, got %v", tt.path, tt.want, body["handler"])
+		}
+	}
+}
+
+func TestGroupErrorHandlerPanic(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+
+	var capturedErr error
+	api.OnErr(func(c *Context, err error) {
+		capturedErr = err
+		_ = c.InternalServerError(nil)
+	})
+	api.GET("/panic", func(c *Context) error {
+		panic("group panic")
+	})
+
+	req := httptest.NewRequest("GET", "/api/panic", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if capturedErr == nil || capturedErr.Error() != "panic: group panic" {
+		t.Errorf("expected panic in group error handler, got %v", capturedErr)
+	}
+}
+
+func TestGroupErrorHandlerFor404(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.OnErr(func(c *Context, err error) {
+		_ = c.BadRequest(M{"error": err.Error()})
+	})
+	api.On404(func(c *Context) error {
+		return errors.New("no such endpoint")
+	})
+
+	req := httptest.NewRequest("GET", "/api/missing", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Errorf("expected 400 from group error handler, got %d", rec.Code)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Edge Cases
 // -----------------------------------------------------------------------------
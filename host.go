@@ -0,0 +1,115 @@
+package mux
+
+import (
+	"net"
+	"strings"
+)
+
+// host is a set of routes served for a host pattern
+type host struct {
+	pattern string
+	labels  []string
+	wild    int
+	mux     Matcher
+}
+
+// Host creates a group whose routes only match requests for the host
+// pattern. Labels in braces are wildcards readable with Context.Param,
+// e.g. "{tenant}.example.com". Routes registered on the router match
+// all hosts and are used when no host route matches.
+func (r *Router) Host(pattern string) *Group {
+	return &Group{
+		router: r,
+		host:   r.host(strings.ToLower(pattern)),
+	}
+}
+
+// host returns the host routes of the table receiving registrations for
+// the lowercase pattern, adding them if needed
+func (r *Router) host(pattern string) *host {
+	var vh *host
+	for _, h := range r.hosts {
+		if h.pattern == pattern {
+			vh = h
+			break
+		}
+	}
+	if vh == nil {
+		vh = &host{
+			pattern: pattern,
+			labels:  strings.Split(pattern, "."),
+			mux:     r.cfg.Matcher(),
+		}
+		for _, l := range vh.labels {
+			if isWildcard(l) {
+				vh.wild++
+			}
+		}
+
+		// literal hosts first, then fewer wildcards, then registration order
+		i := len(r.hosts)
+		for i > 0 && r.hosts[i-1].wild > vh.wild {
+			i--
+		}
+		r.hosts = append(r.hosts, nil)
+		copy(r.hosts[i+1:], r.hosts[i:])
+		r.hosts[i] = vh
+	}
+	return vh
+}
+
+// matchHost returns the host routes for the request host
+func (t *table) matchHost(hostport string) *host {
+	if len(t.hosts) == 0 {
+		return nil
+	}
+	name := strings.ToLower(stripPort(hostport))
+	for _, h := range t.hosts {
+		if h.match(name) {
+			return h
+		}
+	}
+	return nil
+}
+
+// match reports whether the host name matches the pattern
+func (h *host) match(name string) bool {
+	for i, l := range h.labels {
+		label, rest, found := strings.Cut(name, ".")
+		if label == "" || (!isWildcard(l) && l != label) {
+			return false
+		}
+		if !found {
+			return i == len(h.labels)-1
+		}
+		name = rest
+	}
+	return false
+}
+
+// param returns the host label matched by the wildcard name
+func (h *host) param(hostport, name string) string {
+	labels := strings.Split(stripPort(hostport), ".")
+	if len(labels) != len(h.labels) {
+		return ""
+	}
+	for i, l := range h.labels {
+		if isWildcard(l) && l[1:len(l)-1] == name {
+			return labels[i]
+		}
+	}
+	return ""
+}
+
+// isWildcard reports whether a pattern segment is a {name} wildcard
+func isWildcard(s string) bool {
+	return len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}'
+}
+
+// stripPort removes the port from a host
+func stripPort(hostport string) string {
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		return h
+	}
+	return hostport
+}
@@ -0,0 +1,222 @@
+package mux
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Host Routing
+// -----------------------------------------------------------------------------
+
+func TestHostLiteral(t *testing.T) {
+	r := New()
+	api := r.Host("api.example.com")
+	api.GET("/users", func(c *Context) error {
+		return c.OK(M{"host": "api"})
+	})
+	r.GET("/users", func(c *Context) error {
+		return c.OK(M{"host": "any"})
+	})
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "api"},
+		{"api.example.com:8080", "api"},
+		{"API.Example.com", "api"},
+		{"www.example.com", "any"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if body["host"] != tt.want {
+			t.Errorf("%s: expected %s, got %v", tt.host, tt.want, body["host"])
+		}
+	}
+}
+
+func TestHostWildcard(t *testing.T) {
+	r := New()
+	tenant := r.Host("{tenant}.example.com")
+	tenant.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"tenant": c.Param("tenant"), "id": c.Param("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/users/7", nil)
+	req.Host = "acme.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["tenant"] != "acme" {
+		t.Errorf("expected tenant=acme, got %v", body["tenant"])
+	}
+	if body["id"] != "7" {
+		t.Errorf("expected id=7, got %v", body["id"])
+	}
+}
+
+func TestHostWildcardNoMatch(t *testing.T) {
+	r := New()
+	r.Host("{tenant}.example.com").GET("/", func(c *Context) error {
+		return c.OK(nil)
+	})
+
+	for _, host := range []string{"example.com", "a.b.example.com", "acme.example.org"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 404 {
+			t.Errorf("%s: expected 404, got %d", host, rec.Code)
+		}
+	}
+}
+
+func TestHostLiteralBeforeWildcard(t *testing.T) {
+	r := New()
+	r.Host("{tenant}.example.com").GET("/", func(c *Context) error {
+		return c.OK(M{"host": "tenant"})
+	})
+	r.Host("api.example.com").GET("/", func(c *Context) error {
+		return c.OK(M{"host": "api"})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "api.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["host"] != "api" {
+		t.Errorf("expected api, got %v", body["host"])
+	}
+}
+
+func TestHostFallsBackToRouter(t *testing.T) {
+	r := New()
+	r.Host("api.example.com").GET("/users", func(c *Context) error {
+		return c.OK(nil)
+	})
+	r.GET("/health", func(c *Context) error {
+		return c.OK(M{"ok": true})
+	})
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Host = "api.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestHost405(t *testing.T) {
+	r := New()
+	r.Host("api.example.com").GET("/users", func(c *Context) error {
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("POST", "/users", nil)
+	req.Host = "api.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 405 {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestHostGroup(t *testing.T) {
+	r := New()
+	v1 := r.Host("{tenant}.example.com").Group("/v1")
+	v1.GET("/me", func(c *Context) error {
+		return c.OK(M{"tenant": c.Param("tenant")})
+	})
+
+	req := httptest.NewRequest("GET", "/v1/me", nil)
+	req.Host = "globex.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["tenant"] != "globex" {
+		t.Errorf("expected tenant=globex, got %v", body["tenant"])
+	}
+}
+
+func TestHostSamePatternShared(t *testing.T) {
+	r := New()
+	a := r.Host("api.example.com")
+	b := r.Host("API.example.com")
+
+	if a.host != b.host {
+		t.Error("expected groups for the same host to share routes")
+	}
+}
+
+func TestHostRoutes(t *testing.T) {
+	r := New()
+	r.Host("{tenant}.example.com").GET("/users", listUsers)
+
+	routes := r.Routes()
+
+	if routes[0].Host != "{tenant}.example.com" || routes[0].Pattern != "/users" {
+		t.Errorf("expected {tenant}.example.com /users, got %s %s", routes[0].Host, routes[0].Pattern)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Host 404
+// -----------------------------------------------------------------------------
+
+func TestHostCustom404(t *testing.T) {
+	r := New()
+	tenant := r.Host("{tenant}.example.com")
+	tenant.On404(func(c *Context) error {
+		return c.NotFound(M{"tenant": c.Param("tenant")})
+	})
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.Host = "acme.example.com"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if rec.Code != 404 || body["tenant"] != "acme" {
+		t.Errorf("expected tenant 404, got %d %v", rec.Code, body)
+	}
+
+	// Other hosts use the router handler
+	req = httptest.NewRequest("GET", "/missing", nil)
+	req.Host = "example.com"
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	body = nil
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["error"] != "not found" {
+		t.Errorf("expected router 404, got %v", body)
+	}
+}
@@ -0,0 +1,193 @@
+// Package load type checks the packages of a module for the mux command
+// line tools, without depending on golang.org/x/tools
+package load
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// MuxPath is the import path of the mux package
+const MuxPath = "github.com/bamatar/mux"
+
+// Package is a parsed and type checked package
+type Package struct {
+	Path  string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+}
+
+// listed is a package reported by go list
+type listed struct {
+	Dir        string
+	ImportPath string
+	Export     string
+	GoFiles    []string
+	ImportMap  map[string]string
+	DepOnly    bool
+	Error      *struct{ Err string }
+}
+
+// Packages loads the packages matching the patterns in dir. Their
+// dependencies are imported from the export data go list builds.
+func Packages(dir string, patterns ...string) ([]*Package, error) {
+	all, err := list(dir, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make(map[string]string)
+	for _, p := range all {
+		exports[p.ImportPath] = p.Export
+	}
+
+	var pkgs []*Package
+	fset := token.NewFileSet()
+	for _, p := range all {
+		if p.DepOnly {
+			continue
+		}
+		if p.Error != nil {
+			return nil, fmt.Errorf("%s: %s", p.ImportPath, p.Error.Err)
+		}
+
+		pkg := &Package{
+			Path: p.ImportPath,
+			Fset: fset,
+			Info: &types.Info{
+				Types:      make(map[ast.Expr]types.TypeAndValue),
+				Defs:       make(map[*ast.Ident]types.Object),
+				Uses:       make(map[*ast.Ident]types.Object),
+				Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			},
+		}
+		for _, name := range p.GoFiles {
+			f, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			pkg.Files = append(pkg.Files, f)
+		}
+
+		conf := types.Config{
+			Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+				if mapped, ok := p.ImportMap[path]; ok {
+					path = mapped
+				}
+				if exports[path] == "" {
+					return nil, fmt.Errorf("no export data for %s", path)
+				}
+				return os.Open(exports[path])
+			}),
+		}
+		pkg.Types, err = conf.Check(p.ImportPath, fset, pkg.Files, pkg.Info)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// list runs go list for the patterns and their dependencies
+func list(dir string, patterns []string) ([]listed, error) {
+	args := append([]string{"list", "-e", "-export", "-deps", "-json"}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var pkgs []listed
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listed
+		if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// Position returns file:line:column of pos relative to the working
+// directory
+func (p *Package) Position(pos token.Pos) string {
+	position := p.Fset.Position(pos)
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			position.Filename = rel
+		}
+	}
+	return position.String()
+}
+
+// IsMuxType reports whether t is mux.Name or *mux.Name
+func IsMuxType(t types.Type, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == MuxPath && obj.Name() == name
+}
+
+// Assignments calls fn with each variable and value of an assignment or
+// a var declaration, when there is a value for each variable
+func Assignments(n ast.Node, fn func(lhs, rhs ast.Expr)) {
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		if len(n.Lhs) == len(n.Rhs) {
+			for i, lhs := range n.Lhs {
+				fn(lhs, n.Rhs[i])
+			}
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) == len(n.Values) {
+			for i, name := range n.Names {
+				fn(name, n.Values[i])
+			}
+		}
+	}
+}
+
+// MuxMethod returns the selector and receiver type name of a call to a
+// method of a mux type, like "Router" for r.GET(...)
+func MuxMethod(info *types.Info, call *ast.CallExpr) (*ast.SelectorExpr, string, bool) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+	s := info.Selections[sel]
+	if s == nil || s.Kind() != types.MethodVal {
+		return nil, "", false
+	}
+	for _, name := range []string{"Router", "Group", "Route", "Context"} {
+		if IsMuxType(s.Recv(), name) {
+			return sel, name, true
+		}
+	}
+	return nil, "", false
+}
@@ -0,0 +1,57 @@
+package mux
+
+// Key is a typed locals key. Keys are distinct even when their names are
+// equal, and never collide with the string keys of Set and Get.
+type Key[T any] struct {
+	id *keyID
+}
+
+// keyID identifies a Key
+type keyID struct {
+	name string
+}
+
+// NewKey returns a new key for values of type T. The name is used for
+// debugging only.
+//
+//	var UserKey = mux.NewKey[*User]("user")
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{id: &keyID{name: name}}
+}
+
+// keyID returns the identity of the key, for lookups through
+// context.Context
+func (k Key[T]) keyID() *keyID {
+	return k.id
+}
+
+// String returns the name of the key
+func (k Key[T]) String() string {
+	if k.id == nil {
+		return ""
+	}
+	return k.id.name
+}
+
+// Store stores v in the locals of c under the key
+func Store[T any](c *Context, k Key[T], v T) {
+	c.checkReleased()
+	if k.id == nil {
+		panic("mux: Store with a Key not created by NewKey")
+	}
+	c.store(k.id.name, k.id, v)
+}
+
+// Load returns the value stored in the locals of c under the key, and
+// whether there is one
+func Load[T any](c *Context, k Key[T]) (T, bool) {
+	c.checkReleased()
+	if k.id != nil {
+		if v, ok := c.load(k.id.name, k.id); ok {
+			t, ok := v.(T)
+			return t, ok
+		}
+	}
+	var zero T
+	return zero, false
+}
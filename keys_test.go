@@ -0,0 +1,103 @@
+package mux
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type account struct {
+	Name string
+}
+
+var (
+	accountKey = NewKey[*account]("user")
+	roleKey    = NewKey[string]("user")
+)
+
+// -----------------------------------------------------------------------------
+// Typed Keys
+// -----------------------------------------------------------------------------
+
+func TestKeyStoreLoad(t *testing.T) {
+	c := &Context{}
+
+	if _, ok := Load(c, accountKey); ok {
+		t.Error("expected no value before Store")
+	}
+
+	bob := &account{Name: "bob"}
+	Store(c, accountKey, bob)
+	Store(c, roleKey, "admin")
+	c.Set("user", "string key")
+
+	if v, ok := Load(c, accountKey); !ok || v != bob {
+		t.Errorf("expected stored account, got %v %v", v, ok)
+	}
+	if v, ok := Load(c, roleKey); !ok || v != "admin" {
+		t.Errorf("expected keys with equal names to be distinct, got %q", v)
+	}
+	if c.GetString("user") != "string key" {
+		t.Errorf("expected string keys not to collide with typed keys, got %v", c.Get("user"))
+	}
+
+	Store(c, roleKey, "owner")
+	if v, _ := Load(c, roleKey); v != "owner" || len(c.locals) != 3 {
+		t.Errorf("expected Store to replace the value, got %q with %d locals", v, len(c.locals))
+	}
+	if accountKey.String() != "user" {
+		t.Errorf("expected key name, got %q", accountKey.String())
+	}
+}
+
+func TestKeyZeroValue(t *testing.T) {
+	c := &Context{}
+
+	var k Key[int]
+	if _, ok := Load(c, k); ok {
+		t.Error("expected zero key to load nothing")
+	}
+	if v := recovered(func() { Store(c, k, 1) }); v == nil {
+		t.Error("expected Store with a zero key to panic")
+	}
+}
+
+func TestKeyRequestID(t *testing.T) {
+	r := New()
+	r.Use(RequestID())
+	r.GET("/", func(c *Context) error {
+		id, _ := Load(c, RequestIDKey)
+		return c.String(200, id)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "req-1" {
+		t.Errorf("expected request ID under RequestIDKey, got %q", rec.Body.String())
+	}
+}
+
+func TestZeroAllocKeys(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	r := New()
+	bob := &account{Name: "bob"}
+	r.GET("/me", func(c *Context) error {
+		Store(c, accountKey, bob)
+		a, _ := Load(c, accountKey)
+		return c.String(200, a.Name)
+	})
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	w := new(benchWriter)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.ServeHTTP(w, req)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations, got %v", allocs)
+	}
+}
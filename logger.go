@@ -0,0 +1,93 @@
+package mux
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Logger returns a logger with the request ID, method and route pattern
+// of the request and the attributes added with LogWith. It is based on
+// the Logger of the router config.
+func (c *Context) Logger() *slog.Logger {
+	c.checkReleased()
+	logger := slog.Default()
+	if c.router != nil && c.router.cfg.Logger != nil {
+		logger = c.router.cfg.Logger
+	}
+	return logger.With(c.logAttrs()...)
+}
+
+// LogWith adds attributes, as key-value pairs or slog.Attr values, to
+// the loggers of the request
+func (c *Context) LogWith(args ...any) {
+	c.checkReleased()
+	c.logArgs = append(c.logArgs, args...)
+	c.syncLog()
+}
+
+// logAttrs returns the logger attributes of the request
+func (c *Context) logAttrs() []any {
+	args := make([]any, 0, 3+len(c.logArgs))
+	if id, ok := Load(c, RequestIDKey); ok {
+		args = append(args, slog.String("request_id", id))
+	}
+	args = append(args, slog.String("method", c.r.Method))
+	if c.route != nil {
+		args = append(args, slog.String("route", c.route.pattern))
+	}
+	return append(args, c.logArgs...)
+}
+
+// logKey is the context.Context key of the logScope of a request
+type logKey struct{}
+
+// logScope holds the logger attributes of a request for NewLogHandler.
+// It is not pooled and its attributes are replaced, never modified, so
+// records logged from other goroutines read them safely.
+type logScope struct {
+	args atomic.Pointer[[]any]
+}
+
+// attachLog puts a logScope in the request context
+func (c *Context) attachLog() {
+	c.logs = &logScope{}
+	c.syncLog()
+	c.r = c.r.WithContext(context.WithValue(c.r.Context(), logKey{}, c.logs))
+}
+
+// syncLog updates the attributes of the logScope after they changed
+func (c *Context) syncLog() {
+	if c.logs != nil {
+		args := c.logAttrs()
+		c.logs.args.Store(&args)
+	}
+}
+
+// logHandler adds the attributes of the request in the record context
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h to add the attributes Context.Logger has to
+// records logged with the context of a request, like
+// slog.InfoContext(c.Context(), ...). The router must set LogContext.
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return &logHandler{Handler: h}
+}
+
+func (h *logHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if s, ok := ctx.Value(logKey{}).(*logScope); ok {
+		rec = rec.Clone()
+		rec.Add(*s.args.Load()...)
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name)}
+}
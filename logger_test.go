@@ -0,0 +1,137 @@
+package mux
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Request Logger
+// -----------------------------------------------------------------------------
+
+func TestContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+	r.Use(RequestID())
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			c.LogWith("tenant", "acme")
+			return next(c)
+		}
+	})
+
+	api := r.Group("/api")
+	api.GET("/users/{id}", func(c *Context) error {
+		c.LogWith(slog.String("user", c.Param("id")))
+		c.Logger().Info("loading user")
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest("GET", "/api/users/7", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := `msg="loading user" request_id=req-1 method=GET route=/api/users/{id} tenant=acme user=7`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestContextLoggerDefault(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	r := New()
+	r.GET("/", func(c *Context) error {
+		c.Logger().Info("hello")
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !strings.Contains(buf.String(), `msg=hello method=GET route=/`) {
+		t.Errorf("expected default logger with request attributes, got %s", buf.String())
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With("service", "users")
+
+	r := New(Config{LogContext: true})
+	r.Use(RequestID())
+
+	done := make(chan struct{})
+	r.GET("/users", func(c *Context) error {
+		c.LogWith("tenant", "acme")
+		logger.InfoContext(c.Context(), "in handler")
+		c.Go(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			logger.InfoContext(ctx, "in task")
+			close(done)
+			return nil
+		})
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	<-done
+	logger.InfoContext(context.Background(), "outside")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %q", lines)
+	}
+	for i, msg := range []string{`msg="in handler"`, `msg="in task"`} {
+		want := msg + " service=users request_id=req-1 method=GET route=/users tenant=acme"
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("expected %s, got %s", want, lines[i])
+		}
+	}
+	if strings.Contains(lines[2], "request_id") {
+		t.Errorf("expected no request attributes outside requests, got %s", lines[2])
+	}
+}
+
+// TestLogHandlerAfterRelease logs with a request context from another
+// goroutine while the Context goes back to the pool; run with -race
+func TestLogHandlerAfterRelease(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil)))
+
+	r := New(Config{LogContext: true})
+	r.Use(RequestID())
+	ctxs := make(chan context.Context, 1)
+	r.GET("/users", func(c *Context) error {
+		c.LogWith("tenant", "acme")
+		ctxs <- c.Context()
+		return nil
+	})
+	r.GET("/other", func(c *Context) error { return nil })
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx := <-ctxs
+		for i := 0; i < 10; i++ {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+		}
+		logger.InfoContext(ctx, "later")
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	<-done
+
+	want := `msg=later request_id=req-1 method=GET route=/users tenant=acme`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+}
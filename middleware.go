@@ -39,7 +39,11 @@ type RequestIDConfig struct {
 	Generator func() string
 }
 
-// RequestID adds a unique request ID to each request
+// RequestIDKey holds the request ID set by RequestID
+var RequestIDKey = NewKey[string]("request id")
+
+// RequestID adds a unique request ID to each request. The ID is stored
+// under RequestIDKey, and under the header name for Get.
 func RequestID(config ...RequestIDConfig) Middleware {
 	cfg := RequestIDConfig{}
 	if len(config) > 0 {
@@ -66,6 +70,7 @@ func RequestID(config ...RequestIDConfig) Middleware {
 			}
 			c.SetHeader(cfg.Header, id)
 			c.Set(cfg.Header, id)
+			Store(c, RequestIDKey, id)
 			return next(c)
 		}
 	}
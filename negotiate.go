@@ -0,0 +1,92 @@
+package mux
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Render writes v in the media type the Accept header prefers among JSON,
+// XML and, for strings and fmt.Stringers, plain text. JSON is the default.
+// When the client accepts none of them it returns a 406 HTTPError.
+func (c *Context) Render(status int, v any) error {
+	c.checkReleased()
+	c.w.Header().Add("Vary", "Accept")
+
+	var text string
+	offers := []string{MIMEApplicationJSON, MIMEApplicationXML}
+	switch v := v.(type) {
+	case string:
+		text, offers = v, append(offers, MIMETextPlain)
+	case fmt.Stringer:
+		text, offers = v.String(), append(offers, MIMETextPlain)
+	}
+
+	switch negotiate(c.Header("Accept"), offers...) {
+	case MIMEApplicationJSON:
+		return c.JSON(status, v)
+	case MIMEApplicationXML:
+		data, err := xml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, MIMEApplicationXML, data)
+	case MIMETextPlain:
+		return c.String(status, text)
+	}
+	return &HTTPError{Status: http.StatusNotAcceptable, Message: "none of the acceptable media types can be produced"}
+}
+
+// negotiate returns the offered media type the Accept header prefers,
+// the first offer when accept is empty, or "" when none is acceptable
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality the most specific matching media
+// range of an Accept header gives the media type
+func acceptQuality(accept, mediatype string) float64 {
+	typ, _, _ := strings.Cut(mediatype, "/")
+
+	q, specificity := 0.0, 0
+	for part := range strings.SplitSeq(accept, ",") {
+		rng, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		s := 0
+		switch {
+		case rng == mediatype:
+			s = 3
+		case rng == typ+"/*":
+			s = 2
+		case rng == "*/*":
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
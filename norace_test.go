@@ -0,0 +1,7 @@
+//go:build !race
+
+package mux
+
+// raceEnabled reports whether the race detector is on, which allocates
+// where the tests count allocations
+const raceEnabled = false
@@ -0,0 +1,601 @@
+package mux
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// OpenAPI is an OpenAPI 3.1 document
+type OpenAPI struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL         string                     `json:"url"`
+	Description string                     `json:"description,omitempty"`
+	Variables   map[string]*ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable is a variable of a server URL template
+type ServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty"`
+}
+
+// Operation returns the operation for the method, or nil
+func (p *PathItem) Operation(method string) *Operation {
+	if op := p.operation(method); op != nil {
+		return *op
+	}
+	return nil
+}
+
+// operation returns the field of the operation for the method
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodOptions:
+		return &p.Options
+	case http.MethodHead:
+		return &p.Head
+	case http.MethodPatch:
+		return &p.Patch
+	case http.MethodTrace:
+		return &p.Trace
+	}
+	return nil
+}
+
+// Operation describes a route
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+	Servers     []Server              `json:"servers,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the request body of an operation
+type RequestBody struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType is the schema of a body in one media type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Components holds the schemas and security schemes referenced by the
+// document
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	Parameters      map[string]*Parameter      `json:"parameters,omitempty"`
+	RequestBodies   map[string]*RequestBody    `json:"requestBodies,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type             string      `json:"type"`
+	Description      string      `json:"description,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	In               string      `json:"in,omitempty"`
+	Scheme           string      `json:"scheme,omitempty"`
+	BearerFormat     string      `json:"bearerFormat,omitempty"`
+	Flows            *OAuthFlows `json:"flows,omitempty"`
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+}
+
+// OAuthFlows are the OAuth 2 flows of a security scheme
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow is an OAuth 2 flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// SecurityRequirement maps security scheme names to required scopes
+type SecurityRequirement map[string][]string
+
+// OpenAPIConfig configures the generated document
+type OpenAPIConfig struct {
+	// Title of the API. Default: API
+	Title string
+
+	// Version of the document. Default: 1.0.0
+	Version string
+
+	Description string
+	Servers     []Server
+
+	// SecuritySchemes referenced by Route.Security and Security
+	SecuritySchemes map[string]*SecurityScheme
+
+	// Security required by all operations unless they declare their own
+	Security []SecurityRequirement
+
+	// APIVersion limits the document to unversioned routes and the routes
+	// of this API version
+	APIVersion string
+
+	// Host limits the document to the routes of this host pattern and the
+	// routes of all hosts, preferring the former. Without it, routes of all
+	// hosts are preferred and the routes of a host pattern declare it as
+	// their server.
+	Host string
+}
+
+// api is the OpenAPI declaration of a route
+type api struct {
+	summary     string
+	description string
+	request     reflect.Type
+	responses   []apiResponse
+	security    []SecurityRequirement
+
+	// responses were inferred from a typed handler and are replaced by
+	// declared ones
+	inferred bool
+}
+
+// apiResponse is a declared response; a nil type has no content
+type apiResponse struct {
+	status int
+	typ    reflect.Type
+}
+
+// Request declares the type the route binds requests into. Fields tagged
+// path, query or header are parameters, the JSON fields are the body.
+func (rt *Route) Request(v any) *Route {
+	rt.openAPI().request = reflect.TypeOf(v)
+	return rt
+}
+
+// Response declares the type of a response with the status. A nil value
+// declares a response without content.
+func (rt *Route) Response(status int, v any) *Route {
+	a := rt.openAPI()
+	if a.inferred {
+		a.responses, a.inferred = nil, false
+	}
+	a.responses = append(a.responses, apiResponse{status: status, typ: reflect.TypeOf(v)})
+	return rt
+}
+
+// Summary sets the summary and description of the route in the OpenAPI
+// document
+func (rt *Route) Summary(summary string, description ...string) *Route {
+	a := rt.openAPI()
+	a.summary = summary
+	a.description = strings.Join(description, "\n\n")
+	return rt
+}
+
+// Security declares that the route requires the security scheme with
+// the scopes
+func (rt *Route) Security(scheme string, scopes ...string) *Route {
+	a := rt.openAPI()
+	a.security = append(a.security, SecurityRequirement{scheme: append([]string{}, scopes...)})
+	return rt
+}
+
+func (rt *Route) openAPI() *api {
+	if rt.api == nil {
+		rt.api = &api{}
+	}
+	return rt.api
+}
+
+// OpenAPI generates an OpenAPI 3.1 document from the registered routes.
+// Mounted handlers are not described.
+func (r *Router) OpenAPI(config ...OpenAPIConfig) *OpenAPI {
+	var cfg OpenAPIConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Title == "" {
+		cfg.Title = "API"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "1.0.0"
+	}
+
+	doc := &OpenAPI{
+		OpenAPI:  "3.1.0",
+		Info:     Info{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description},
+		Servers:  cfg.Servers,
+		Paths:    make(map[string]*PathItem),
+		Security: cfg.Security,
+	}
+	b := newSchemaBuilder()
+	ids := make(map[string]bool)
+	hosts := make(map[string]string) // host of each described path and method
+
+	// rank orders the routes of a host for describing a path and method
+	rank := func(host string) int {
+		switch {
+		case host == "":
+			return 1
+		case host == cfg.Host:
+			return 2
+		}
+		return 0
+	}
+
+	for _, rt := range r.live.Load().routes {
+		if rt.method == "" || rt.version != "" && cfg.APIVersion != "" && rt.version != cfg.APIVersion {
+			continue
+		}
+		if cfg.Host != "" && rt.host != "" && rt.host != cfg.Host {
+			continue
+		}
+
+		pattern := rt.pattern
+		if rt.version != "" && r.cfg.Versioning.Strategy&VersionPath != 0 {
+			pattern = rt.vpattern
+		}
+		path, params := openAPIPath(pattern)
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		op := item.operation(rt.method)
+		if op == nil {
+			continue
+		}
+		key := rt.method + " " + path
+		if *op != nil {
+			// the first version of a pattern describes it, and the first
+			// host pattern of the others
+			if rank(rt.host) <= rank(hosts[key]) {
+				continue
+			}
+			delete(ids, (*op).OperationID)
+		}
+		hosts[key] = rt.host
+		*op = r.operation(b, rt, params, ids)
+		if rt.host != "" && rt.host != cfg.Host {
+			(*op).Servers = []Server{hostServer(rt.host)}
+		}
+	}
+
+	if len(b.defs) > 0 || len(cfg.SecuritySchemes) > 0 {
+		doc.Components = &Components{SecuritySchemes: cfg.SecuritySchemes}
+		if len(b.defs) > 0 {
+			doc.Components.Schemas = b.defs
+		}
+	}
+	return doc
+}
+
+// hostServer returns the server of a host pattern, its wildcard labels
+// becoming variables
+func hostServer(pattern string) Server {
+	s := Server{URL: "//" + pattern}
+	for _, label := range strings.Split(pattern, ".") {
+		if isWildcard(label) {
+			if s.Variables == nil {
+				s.Variables = make(map[string]*ServerVariable)
+			}
+			s.Variables[label[1:len(label)-1]] = &ServerVariable{Default: label[1 : len(label)-1]}
+		}
+	}
+	return s
+}
+
+// OpenAPIHandler returns a handler that serves the OpenAPI document of
+// the current routes. Register it on the route of your choice.
+func (r *Router) OpenAPIHandler(config ...OpenAPIConfig) Handler {
+	return func(c *Context) error {
+		return c.OK(r.OpenAPI(config...))
+	}
+}
+
+// operation describes the route rt with the path wildcards params
+func (r *Router) operation(b *schemaBuilder, rt *Route, params []pathParam, ids map[string]bool) *Operation {
+	a := rt.api
+	if a == nil {
+		a = &api{}
+	}
+
+	op := &Operation{
+		OperationID: operationID(rt, ids),
+		Summary:     a.summary,
+		Description: a.description,
+		Tags:        rt.tags,
+		Responses:   make(map[string]*Response),
+		Deprecated:  rt.deprecation != nil,
+		Security:    a.security,
+	}
+
+	var fields map[string]*Parameter
+	if a.request != nil {
+		fields = paramFields(b, a.request)
+	}
+
+	for _, p := range params {
+		param := fields["path "+p.name]
+		if param == nil {
+			param = &Parameter{Name: p.name, In: "path", Schema: constraintSchema(p.expr)}
+		}
+		param.Required = true
+		op.Parameters = append(op.Parameters, param)
+	}
+	if a.request != nil {
+		for _, param := range orderedParams(a.request, fields) {
+			op.Parameters = append(op.Parameters, param)
+		}
+	}
+
+	vc := r.cfg.Versioning
+	if rt.version != "" && vc.Strategy&VersionPath == 0 && vc.Strategy&VersionHeader != 0 {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     vc.Header,
+			In:       "header",
+			Required: vc.Default == "",
+			Schema:   &Schema{Type: SchemaType{"string"}, Enum: []any{rt.version}},
+		})
+	}
+
+	if a.request != nil && hasBody(rt.method, a.request) {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{MIMEApplicationJSON: {Schema: b.schema(a.request)}},
+		}
+	}
+
+	for _, res := range a.responses {
+		resp := &Response{Description: http.StatusText(res.status)}
+		if res.typ != nil {
+			resp.Content = map[string]*MediaType{MIMEApplicationJSON: {Schema: b.schema(res.typ)}}
+		}
+		op.Responses[strconv.Itoa(res.status)] = resp
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{Description: "Response"}
+	}
+	return op
+}
+
+// pathParam is a wildcard of a pattern and its constraint expression
+type pathParam struct {
+	name string
+	expr string
+}
+
+// openAPIPath converts a pattern to an OpenAPI path template and returns
+// its wildcards. Constraints, the ... suffix and {$} are dropped.
+func openAPIPath(pattern string) (string, []pathParam) {
+	var sb strings.Builder
+	var params []pathParam
+
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 {
+			break
+		}
+		end := closingBrace(pattern, i)
+		if end < 0 {
+			break
+		}
+
+		sb.WriteString(pattern[:i])
+		name, expr, _ := strings.Cut(pattern[i+1:end], ":")
+		name = strings.TrimSuffix(name, "...")
+		pattern = pattern[end+1:]
+		if name == "$" {
+			continue
+		}
+
+		sb.WriteString("{" + name + "}")
+		params = append(params, pathParam{name: name, expr: expr})
+	}
+	sb.WriteString(pattern)
+
+	return sb.String(), params
+}
+
+// constraintSchema returns the schema of values matching a wildcard
+// constraint
+func constraintSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: SchemaType{"string"}}
+	case "int":
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+	case "uuid":
+		return &Schema{Type: SchemaType{"string"}, Format: "uuid"}
+	}
+	return &Schema{Type: SchemaType{"string"}, Pattern: "^(?:" + expr + ")$"}
+}
+
+// paramFields returns the parameters declared by the fields of the
+// request type t, keyed by location and name
+func paramFields(b *schemaBuilder, t reflect.Type) map[string]*Parameter {
+	params := make(map[string]*Parameter)
+	eachParamField(t, func(f reflect.StructField, in, name string) {
+		s := b.schema(f.Type)
+		required := applyValidate(s, f.Type, f.Tag.Get("validate"))
+		params[in+" "+name] = &Parameter{
+			Name:        name,
+			In:          in,
+			Description: f.Tag.Get("doc"),
+			Required:    required,
+			Schema:      s,
+		}
+	})
+	return params
+}
+
+// orderedParams returns the query and header parameters of fields in the
+// field order of t
+func orderedParams(t reflect.Type, fields map[string]*Parameter) []*Parameter {
+	var params []*Parameter
+	eachParamField(t, func(f reflect.StructField, in, name string) {
+		if in != "path" {
+			params = append(params, fields[in+" "+name])
+		}
+	})
+	return params
+}
+
+// eachParamField calls fn for each field of the struct type t bound from
+// the path, query or headers, including fields of embedded structs
+func eachParamField(t reflect.Type, fn func(f reflect.StructField, in, name string)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag == "" {
+			eachParamField(f.Type, fn)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		for _, in := range []string{"path", "query", "header"} {
+			if name, _, _ := strings.Cut(f.Tag.Get(in), ","); name != "" && name != "-" {
+				fn(f, in, name)
+			}
+		}
+	}
+}
+
+// hasBody reports whether requests of the method bind a body into t
+func hasBody(method string, t reflect.Type) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	return len(newSchemaBuilder().object(t).Properties) > 0
+}
+
+// operationID returns the route name, or an id derived from the method
+// and path like getUsersById, made unique with a numeric suffix
+func operationID(rt *Route, ids map[string]bool) string {
+	id := rt.name
+	if id == "" {
+		var sb strings.Builder
+		sb.WriteString(strings.ToLower(rt.method))
+		path, _ := openAPIPath(rt.pattern)
+		if rt.version != "" {
+			sb.WriteString("V" + rt.version)
+		}
+		for _, seg := range strings.Split(path, "/") {
+			if strings.HasPrefix(seg, "{") {
+				sb.WriteString("By")
+				seg = strings.Trim(seg, "{}")
+			}
+			sb.WriteString(camel(seg))
+		}
+		id = sb.String()
+	}
+
+	unique := id
+	for n := 2; ids[unique]; n++ {
+		unique = id + strconv.Itoa(n)
+	}
+	ids[unique] = true
+	return unique
+}
+
+// camel converts a path segment like user-accounts to UserAccounts
+func camel(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,299 @@
+package mux
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type createUser struct {
+	Org    string `path:"org"`
+	DryRun bool   `query:"dry_run"`
+	Trace  string `header:"X-Trace" validate:"required"`
+
+	Name  string   `json:"name" validate:"required,min=2,max=64"`
+	Email string   `json:"email" validate:"required,email"`
+	Role  string   `json:"role,omitempty" validate:"oneof=admin member"`
+	Age   int      `json:"age" validate:"gte=0,lt=150"`
+	Tags  []string `json:"tags" validate:"max=5,dive,alphanum"`
+}
+
+type user struct {
+	ID      UUID      `json:"id"`
+	Name    string    `json:"name" doc:"Display name"`
+	Created time.Time `json:"created"`
+	Manager *user     `json:"manager,omitempty"`
+	secret  string
+}
+
+func openAPIRouter() *Router {
+	r := New()
+	r.POST("/orgs/{org}/users", listUsers).
+		Name("createUser").
+		Request(createUser{}).
+		Response(201, user{}).
+		Response(409, nil).
+		Summary("Create a user").
+		Security("bearer").
+		Tags("users")
+	r.GET("/orgs/{org}/users/{id:uuid}", listUsers).Response(200, &user{})
+	r.GET("/files/{path...}", listUsers)
+	r.Mount("/legacy", nil)
+	return r
+}
+
+// -----------------------------------------------------------------------------
+// OpenAPI
+// -----------------------------------------------------------------------------
+
+func TestOpenAPIPathsAndParameters(t *testing.T) {
+	doc := openAPIRouter().OpenAPI(OpenAPIConfig{Title: "Users"})
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Users" || doc.Info.Version != "1.0.0" {
+		t.Errorf("unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("expected 3 paths without the mount, got %v", reflect.ValueOf(doc.Paths).MapKeys())
+	}
+
+	get := doc.Paths["/orgs/{org}/users/{id}"].Operation("GET")
+	if get == nil || get.OperationID != "getOrgsByOrgUsersById" {
+		t.Fatalf("expected derived operation, got %+v", get)
+	}
+	if id := get.Parameters[1]; id.Name != "id" || id.In != "path" || !id.Required || id.Schema.Format != "uuid" {
+		t.Errorf("expected uuid path parameter, got %+v", id)
+	}
+	if get.RequestBody != nil {
+		t.Error("expected no request body for GET")
+	}
+
+	files := doc.Paths["/files/{path}"].Get
+	if files == nil || files.Parameters[0].Name != "path" || files.Responses["default"] == nil {
+		t.Errorf("expected catch-all parameter and default response, got %+v", files)
+	}
+}
+
+func TestOpenAPIRequestAndResponses(t *testing.T) {
+	doc := openAPIRouter().OpenAPI()
+	op := doc.Paths["/orgs/{org}/users"].Post
+
+	if op.OperationID != "createUser" || op.Summary != "Create a user" || op.Tags[0] != "users" {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	if len(op.Security) != 1 || op.Security[0]["bearer"] == nil {
+		t.Errorf("expected bearer requirement, got %+v", op.Security)
+	}
+
+	var in []string
+	for _, p := range op.Parameters {
+		in = append(in, p.In+":"+p.Name)
+	}
+	if !reflect.DeepEqual(in, []string{"path:org", "query:dry_run", "header:X-Trace"}) {
+		t.Errorf("unexpected parameters %v", in)
+	}
+	if !op.Parameters[2].Required || op.Parameters[1].Schema.Type[0] != "boolean" {
+		t.Errorf("unexpected parameter schemas %+v %+v", op.Parameters[1], op.Parameters[2])
+	}
+
+	body := doc.Components.Schemas["createUser"]
+	if op.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/createUser" {
+		t.Errorf("expected body reference, got %+v", op.RequestBody.Content["application/json"].Schema)
+	}
+	if len(body.Properties) != 5 || !reflect.DeepEqual(body.Required, []string{"name", "email"}) {
+		t.Errorf("expected body fields only, got %+v", body)
+	}
+
+	if op.Responses["201"].Content["application/json"].Schema.Ref != "#/components/schemas/user" {
+		t.Errorf("expected user response, got %+v", op.Responses["201"])
+	}
+	if r := op.Responses["409"]; r.Description != "Conflict" || r.Content != nil {
+		t.Errorf("expected content-less 409, got %+v", r)
+	}
+}
+
+func TestOpenAPIValidateTags(t *testing.T) {
+	body := openAPIRouter().OpenAPI().Components.Schemas["createUser"]
+
+	name := body.Properties["name"]
+	if *name.MinLength != 2 || *name.MaxLength != 64 {
+		t.Errorf("expected length bounds, got %+v", name)
+	}
+	if body.Properties["email"].Format != "email" {
+		t.Error("expected email format")
+	}
+	if !reflect.DeepEqual(body.Properties["role"].Enum, []any{"admin", "member"}) {
+		t.Errorf("expected enum, got %v", body.Properties["role"].Enum)
+	}
+	age := body.Properties["age"]
+	if *age.Minimum != 0 || *age.ExclusiveMaximum != 150 {
+		t.Errorf("expected numeric bounds, got %+v", age)
+	}
+	tags := body.Properties["tags"]
+	if *tags.MaxItems != 5 || tags.Items.Pattern != "^[a-zA-Z0-9]+$" {
+		t.Errorf("expected item count and dived pattern, got %+v", tags)
+	}
+}
+
+func TestOpenAPISchemaTypes(t *testing.T) {
+	u := openAPIRouter().OpenAPI().Components.Schemas["user"]
+
+	tests := map[string]string{"id": "uuid", "created": "date-time"}
+	for name, format := range tests {
+		if s := u.Properties[name]; s.Type[0] != "string" || s.Format != format {
+			t.Errorf("%s: expected string %s, got %+v", name, format, s)
+		}
+	}
+	if u.Properties["name"].Description != "Display name" {
+		t.Error("expected doc tag as description")
+	}
+	if u.Properties["manager"].Ref != "#/components/schemas/user" {
+		t.Errorf("expected recursive reference, got %+v", u.Properties["manager"])
+	}
+	if _, ok := u.Properties["secret"]; ok {
+		t.Error("expected unexported field to be skipped")
+	}
+}
+
+func TestOpenAPIVersions(t *testing.T) {
+	r := versionedRouter(VersionConfig{})
+	doc := r.OpenAPI()
+	if doc.Paths["/v1/users"] == nil || doc.Paths["/v2/teams"] == nil {
+		t.Errorf("expected version prefixes, got %v", reflect.ValueOf(doc.Paths).MapKeys())
+	}
+	if id := doc.Paths["/v2/users"].Get.OperationID; id != "getV2Users" {
+		t.Errorf("expected versioned operation id, got %s", id)
+	}
+
+	r = versionedRouter(VersionConfig{Strategy: VersionHeader})
+	doc = r.OpenAPI(OpenAPIConfig{APIVersion: "2"})
+	op := doc.Paths["/users"].Get
+	if op == nil || op.Parameters[0].Name != "API-Version" || op.Parameters[0].Schema.Enum[0] != "2" {
+		t.Errorf("expected version header parameter, got %+v", op)
+	}
+}
+
+func TestOpenAPIHosts(t *testing.T) {
+	r := New()
+	r.Host("api.example.com").GET("/status", listUsers).Name("apiStatus")
+	r.GET("/status", listUsers).Name("status")
+	r.Host("admin.example.com").GET("/status", listUsers).Name("adminStatus")
+	r.Host("{tenant}.example.com").GET("/billing", listUsers).Name("billing")
+
+	doc := r.OpenAPI()
+	if op := doc.Paths["/status"].Get; op.OperationID != "status" || op.Servers != nil {
+		t.Errorf("expected the route of all hosts, got %+v", op)
+	}
+	op := doc.Paths["/billing"].Get
+	if len(op.Servers) != 1 || op.Servers[0].URL != "//{tenant}.example.com" || op.Servers[0].Variables["tenant"] == nil {
+		t.Errorf("expected the host as server, got %+v", op.Servers)
+	}
+
+	doc = r.OpenAPI(OpenAPIConfig{Host: "admin.example.com"})
+	if op := doc.Paths["/status"].Get; op.OperationID != "adminStatus" || op.Servers != nil {
+		t.Errorf("expected the route of the host, got %+v", op)
+	}
+	if doc.Paths["/billing"] != nil {
+		t.Error("expected routes of other hosts to be skipped")
+	}
+}
+
+func TestOpenAPIHandlerHosts(t *testing.T) {
+	r := New()
+	r.Host("api.example.com").GET("/users", listUsers)
+	r.GET("/users", listUsers)
+	r.GET("/openapi.json", r.OpenAPIHandler())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+	if rec.Code != 200 {
+		t.Errorf("expected document, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestOpenAPINullable(t *testing.T) {
+	type account struct {
+		Email   *string `json:"email"`
+		Phone   *string `json:"phone,omitempty"`
+		Owner   *user   `json:"owner"`
+		Dash    string  `json:"-,"`
+		Skipped string  `json:"-"`
+	}
+	r := New()
+	r.GET("/account", listUsers).Response(200, account{})
+	s := r.OpenAPI().Components.Schemas["account"]
+
+	if email := s.Properties["email"]; !reflect.DeepEqual(email.Type, SchemaType{"string", "null"}) {
+		t.Errorf("expected nullable string, got %+v", email)
+	}
+	if phone := s.Properties["phone"]; len(phone.Type) != 1 {
+		t.Errorf("expected omitempty pointer not to be null, got %+v", phone)
+	}
+	if owner := s.Properties["owner"]; len(owner.AnyOf) != 2 || owner.AnyOf[0].Ref != "#/components/schemas/user" {
+		t.Errorf("expected reference or null, got %+v", owner)
+	}
+	if _, ok := s.Properties["-"]; !ok {
+		t.Error(`expected field named "-"`)
+	}
+	if _, ok := s.Properties["Skipped"]; ok {
+		t.Error("expected skipped field")
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	r := openAPIRouter()
+	r.GET("/openapi.json", r.OpenAPIHandler(OpenAPIConfig{
+		SecuritySchemes: map[string]*SecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("expected JSON document: %v", err)
+	}
+	components := doc["components"].(map[string]any)
+	if components["securitySchemes"].(map[string]any)["bearer"] == nil {
+		t.Errorf("expected security scheme, got %v", components)
+	}
+	schema := components["schemas"].(map[string]any)["user"].(map[string]any)
+	if schema["type"] != "object" {
+		t.Errorf("expected type as string, got %v", schema["type"])
+	}
+	if doc["paths"].(map[string]any)["/openapi.json"] == nil {
+		t.Error("expected the document route itself")
+	}
+}
+
+func TestSchemaTypeJSON(t *testing.T) {
+	var s Schema
+	if err := json.Unmarshal([]byte(`{"type":["string","null"],"additionalProperties":false}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Type.Has("null") || s.AdditionalProperties.Not == nil {
+		t.Errorf("expected type list and false schema, got %+v", s)
+	}
+	data, _ := json.Marshal(s)
+	if string(data) != `{"type":["string","null"],"additionalProperties":{"not":{}}}` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+}
+
+func TestSchemaNullable(t *testing.T) {
+	var s Schema
+	if err := json.Unmarshal([]byte(`{"type":"string","nullable":true}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Type, SchemaType{"string", "null"}) {
+		t.Errorf("expected null in the type list, got %v", s.Type)
+	}
+	data, _ := json.Marshal(s)
+	if string(data) != `{"type":["string","null"]}` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+}
@@ -0,0 +1,195 @@
+package mux
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// TrailingSlash is the policy for paths that differ from a registered
+// route only by a trailing slash
+type TrailingSlash int
+
+const (
+	// TrailingSlashDefault follows http.ServeMux, which redirects /tree
+	// to a registered /tree/ and treats other mismatches as not found
+	TrailingSlashDefault TrailingSlash = iota
+
+	// TrailingSlashStrict matches paths exactly as registered
+	TrailingSlashStrict
+
+	// TrailingSlashRedirect redirects to the registered form of the path
+	TrailingSlashRedirect
+
+	// TrailingSlashMatch serves the registered route for both forms
+	TrailingSlashMatch
+)
+
+// lookup returns the mux of the table t with a route matching the
+// request and the matched pattern, checking the host routes first
+func (r *Router) lookup(t *table, vh *host, req *http.Request) (Matcher, string) {
+	if vh != nil {
+		if p := r.matched(vh.mux, req); p != "" {
+			return vh.mux, p
+		}
+	}
+	if p := r.matched(t.mux, req); p != "" {
+		return t.mux, p
+	}
+	return nil, ""
+}
+
+// matched returns the pattern mux matches for the request
+func (r *Router) matched(mux Matcher, req *http.Request) string {
+	_, p := mux.Handler(req)
+	if p == "" || r.cfg.TrailingSlash == TrailingSlashDefault {
+		return p
+	}
+	// the matcher reports its /tree to /tree/ redirect as a match
+	if !strings.HasSuffix(req.URL.Path, "/") && strings.HasSuffix(p, "/") {
+		return ""
+	}
+	return p
+}
+
+// canonical serves requests whose path differs from a registered route
+// only by cleaning, case or trailing slash, as allowed by the router config
+func (r *Router) canonical(t *table, rsp *responder, req *http.Request, vh *host) bool {
+	policy := r.cfg.TrailingSlash
+	if cleanPath(req.URL.Path) != req.URL.Path {
+		// the mux redirect to the clean path passed through unless
+		// the policy is strict or redirects with its own status
+		if policy != TrailingSlashRedirect {
+			return false
+		}
+		req = withPath(req, cleanPath)
+		if mux, _ := r.lookup(t, vh, req); mux != nil {
+			r.redirect(rsp, req)
+			return true
+		}
+	}
+
+	if r.cfg.CaseInsensitive {
+		if req2, mux := r.fold(t, vh, req); mux != nil {
+			mux.ServeHTTP(rsp, req2)
+			return true
+		}
+	}
+
+	if policy != TrailingSlashRedirect && policy != TrailingSlashMatch || req.URL.Path == "/" {
+		return false
+	}
+
+	req2 := withPath(req, func(p string) string {
+		if strings.HasSuffix(p, "/") {
+			return strings.TrimSuffix(p, "/")
+		}
+		return p + "/"
+	})
+
+	mux, _ := r.lookup(t, vh, req2)
+	if mux == nil && r.cfg.CaseInsensitive {
+		req2, mux = r.fold(t, vh, req2)
+	}
+	if mux == nil {
+		return false
+	}
+
+	if policy == TrailingSlashRedirect {
+		r.redirect(rsp, req2)
+		return true
+	}
+	mux.ServeHTTP(rsp, req2)
+	return true
+}
+
+// redirect sends the client to the path of req with the configured status
+func (r *Router) redirect(rsp *responder, req *http.Request) {
+	_, w := rsp.take()
+	u := url.URL{Path: req.URL.Path, RawPath: req.URL.RawPath, RawQuery: req.URL.RawQuery}
+	http.Redirect(w, req, u.String(), r.cfg.RedirectStatus)
+}
+
+// fold matches the request ignoring case and returns it with the literal
+// segments of the matched route
+func (r *Router) fold(t *table, vh *host, req *http.Request) (*http.Request, Matcher) {
+	_, p := r.lookup(t, vh, withPath(req, strings.ToLower))
+	if p == "" {
+		return nil, nil
+	}
+
+	pattern := p[strings.IndexByte(p, '/'):]
+	req2 := withPath(req, func(p string) string { return restoreCase(pattern, p) })
+	mux, _ := r.lookup(t, vh, req2)
+	return req2, mux
+}
+
+// restoreCase rebuilds p with the literal segments of pattern, keeping
+// the segments of p matched by wildcards
+func restoreCase(pattern, p string) string {
+	var sb strings.Builder
+	for pattern != "" && p != "" {
+		var want, seg string
+		want, pattern = nextSegment(pattern)
+		if want == "" || strings.HasSuffix(want, "...}") {
+			// subtree or multi-segment wildcard keeps the rest
+			break
+		}
+		seg, p = nextSegment(p)
+		sb.WriteByte('/')
+		if strings.Contains(want, "{") {
+			sb.WriteString(seg)
+		} else {
+			sb.WriteString(want)
+		}
+	}
+	sb.WriteString(p)
+	return sb.String()
+}
+
+// lowerLiterals lowercases the literal segments of a pattern path
+func lowerLiterals(pattern string) string {
+	segs := strings.Split(pattern, "/")
+	for i, seg := range segs {
+		if !strings.Contains(seg, "{") {
+			segs[i] = strings.ToLower(seg)
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// cleanPath collapses repeated slashes and resolves . and .. segments,
+// keeping a trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] == '/' && !strings.Contains(p, "//") && !strings.Contains(p, "/.") {
+		return p
+	}
+	np := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+// withPath returns a shallow copy of req with transform applied to the
+// URL path. An escaped path is transformed too, so that encoded slashes
+// stay inside their segment, and dropped if it no longer encodes the path.
+func withPath(req *http.Request, transform func(string) string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *req
+	r2.URL = new(url.URL)
+	*r2.URL = *req.URL
+	r2.URL.Path = transform(req.URL.Path)
+	r2.URL.RawPath = ""
+	if req.URL.RawPath != "" {
+		raw := transform(req.URL.EscapedPath())
+		if p, err := url.PathUnescape(raw); err == nil && p == r2.URL.Path {
+			r2.URL.RawPath = raw
+		}
+	}
+	return r2
+}
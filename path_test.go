@@ -0,0 +1,339 @@
+package mux
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Trailing Slash
+// -----------------------------------------------------------------------------
+
+func TestTrailingSlashDefault(t *testing.T) {
+	r := New()
+	r.GET("/tree/", func(c *Context) error { return c.OK(nil) })
+	r.GET("/leaf", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "/tree", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code < 300 || rec.Code >= 400 || rec.Header().Get("Location") != "/tree/" {
+		t.Errorf("expected redirect to /tree/, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest("GET", "/leaf/", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestTrailingSlashStrict(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashStrict})
+	r.GET("/tree/", func(c *Context) error { return c.OK(nil) })
+	r.GET("/leaf", func(c *Context) error { return c.OK(nil) })
+
+	for _, path := range []string{"/tree", "/leaf/"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 404 {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/tree/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashRedirect})
+	r.GET("/tree/", func(c *Context) error { return c.OK(nil) })
+	r.GET("/leaf", func(c *Context) error { return c.OK(nil) })
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/tree", "/tree/"},
+		{"/leaf/", "/leaf"},
+		{"/leaf/?q=1", "/leaf?q=1"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 301 {
+			t.Errorf("%s: expected 301, got %d", tt.path, rec.Code)
+		}
+		if loc := rec.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s: expected Location %s, got %s", tt.path, tt.location, loc)
+		}
+	}
+}
+
+func TestTrailingSlashRedirectStatus(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashRedirect, RedirectStatus: 308})
+	r.POST("/leaf", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("POST", "/leaf/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 308 {
+		t.Errorf("expected 308, got %d", rec.Code)
+	}
+}
+
+func TestTrailingSlashRedirectCleanPath(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashRedirect, RedirectStatus: 308})
+	r.GET("/a", func(c *Context) error { return c.OK(nil) })
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"//a", "/a"},
+		{"/x/../a?q=1", "/a?q=1"},
+		{"//a/", "/a"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.path, "?")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 308 {
+			t.Errorf("%s: expected 308, got %d", tt.path, rec.Code)
+		}
+		if loc := rec.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s: expected Location %s, got %s", tt.path, tt.location, loc)
+		}
+	}
+}
+
+func TestTrailingSlashStrictCleanPath(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashStrict})
+	r.GET("/a", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.URL.Path = "//a"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestTrailingSlashMatch(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashMatch})
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.Param("id")})
+	})
+	r.GET("/tree/", func(c *Context) error { return c.OK(M{"id": "tree"}) })
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users/7", "7"},
+		{"/users/7/", "7"},
+		{"/tree", "tree"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if rec.Code != 200 || body["id"] != tt.want {
+			t.Errorf("%s: expected %s, got %d %v", tt.path, tt.want, rec.Code, body)
+		}
+	}
+}
+
+func TestTrailingSlashMissStill404(t *testing.T) {
+	r := New(Config{TrailingSlash: TrailingSlashMatch})
+	r.GET("/users", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "/posts/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Case Insensitive
+// -----------------------------------------------------------------------------
+
+func TestCaseInsensitive(t *testing.T) {
+	r := New(Config{CaseInsensitive: true})
+	r.GET("/Users/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.Param("id"), "path": c.Path()})
+	})
+
+	for _, path := range []string{"/users/AbC", "/USERS/AbC", "/Users/AbC"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if rec.Code != 200 || body["id"] != "AbC" {
+			t.Errorf("%s: expected id=AbC, got %d %v", path, rec.Code, body)
+		}
+	}
+}
+
+func TestCaseSensitiveByDefault(t *testing.T) {
+	r := New()
+	r.GET("/users", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "/USERS", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestCaseInsensitiveTrailingSlashRedirect(t *testing.T) {
+	r := New(Config{CaseInsensitive: true, TrailingSlash: TrailingSlashRedirect})
+	r.GET("/users/{id}", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "/Users/AbC/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 301 || rec.Header().Get("Location") != "/users/AbC" {
+		t.Errorf("expected redirect to /users/AbC, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Clean Path
+// -----------------------------------------------------------------------------
+
+func TestCleanPath(t *testing.T) {
+	r := New(Config{CleanPath: true})
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.OK(M{"id": c.Param("id")})
+	})
+
+	for _, path := range []string{"//users//42", "/users/./42", "/posts/../users/42"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var body M
+		json.Unmarshal(rec.Body.Bytes(), &body)
+
+		if rec.Code != 200 || body["id"] != "42" {
+			t.Errorf("%s: expected id=42, got %d %v", path, rec.Code, body)
+		}
+	}
+}
+
+func TestEncodedSlashKept(t *testing.T) {
+	handler := func(c *Context) error { return c.String(200, c.Param("name")) }
+
+	tests := []struct {
+		config Config
+		path   string
+	}{
+		{Config{CleanPath: true}, "/x/../files/a%2Fb"},
+		{Config{CaseInsensitive: true}, "/FILES/a%2Fb"},
+		{Config{TrailingSlash: TrailingSlashMatch}, "/files/a%2Fb/"},
+	}
+	for _, tt := range tests {
+		r := New(tt.config)
+		r.GET("/files/{name}", handler)
+
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 200 || rec.Body.String() != "a/b" {
+			t.Errorf("%s: expected name=a/b, got %d %q", tt.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCleanPathDisabled(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("GET", "//users//42", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code == 200 {
+		t.Error("expected uncleaned path not to be served")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Internal
+// -----------------------------------------------------------------------------
+
+func TestCleanPathFunc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"//a//b", "/a/b"},
+		{"/a/b/", "/a/b/"},
+		{"/a/./b/../c", "/a/c"},
+		{"a", "/a"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanPath(tt.in); got != tt.want {
+			t.Errorf("cleanPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreCase(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    string
+	}{
+		{"/Users/{id}", "/users/AbC", "/Users/AbC"},
+		{"/Static/", "/STATIC/Css/App.css", "/Static/Css/App.css"},
+		{"/Files/{path...}", "/files/A/B", "/Files/A/B"},
+		{"/A/{$}", "/a/", "/A/"},
+	}
+
+	for _, tt := range tests {
+		if got := restoreCase(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("restoreCase(%q, %q) = %q, want %q", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
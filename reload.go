@@ -0,0 +1,53 @@
+package mux
+
+// table is a route table
+type table struct {
+	mux    Matcher
+	routes []*Route
+	hosts  []*host
+
+	// groups with custom handlers
+	scopes []*Group
+
+	// dispatchers of the routes sharing a matcher pattern, by host and
+	// pattern
+	patterns map[string]*siblings
+
+	// middleware added by the Reload callback that built the table
+	mws []Middleware
+
+	// declared API versions and the prefixes of version groups
+	versions  []string
+	vprefixes []string
+}
+
+// newTable returns an empty route table
+func (r *Router) newTable() *table {
+	return &table{mux: r.cfg.Matcher()}
+}
+
+// Reload replaces the route table with the routes fn registers on r.
+// The new table is swapped in atomically once fn returns: in-flight
+// requests finish on the old table and new requests see the new one.
+// Config, middleware added outside fn and router error handlers are
+// kept; middleware fn adds with Use only wraps the routes of the new
+// table. Groups created before the reload register into the table being
+// built, or the live one afterwards, but their error handlers belong to
+// the table they were set in. If fn panics, the old table stays in place.
+func (r *Router) Reload(fn func(*Router)) {
+	r.reload.Lock()
+	defer r.reload.Unlock()
+
+	old := r.table
+	r.table = r.newTable()
+	r.reloading = true
+	defer func() {
+		r.reloading = false
+		if r.table != r.live.Load() {
+			r.table = old
+		}
+	}()
+
+	fn(r)
+	r.live.Store(r.table)
+}
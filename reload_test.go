@@ -0,0 +1,233 @@
+package mux
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Reload
+// -----------------------------------------------------------------------------
+
+func TestReloadReplacesRoutes(t *testing.T) {
+	r := New()
+	r.GET("/old", func(c *Context) error { return c.String(200, "old") })
+
+	r.Reload(func(r *Router) {
+		r.GET("/new", func(c *Context) error { return c.String(200, "new") })
+	})
+
+	req := httptest.NewRequest("GET", "/old", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected removed route to 404, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/new", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 || rec.Body.String() != "new" {
+		t.Errorf("expected new route, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if routes := r.Routes(); len(routes) != 1 || routes[0].Pattern != "/new" {
+		t.Errorf("expected route table with /new only, got %+v", routes)
+	}
+}
+
+func TestReloadReplacesSamePattern(t *testing.T) {
+	r := New()
+	r.GET("/v", func(c *Context) error { return c.String(200, "1") })
+
+	r.Reload(func(r *Router) {
+		r.GET("/v", func(c *Context) error { return c.String(200, "2") })
+	})
+
+	req := httptest.NewRequest("GET", "/v", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "2" {
+		t.Errorf("expected replaced handler, got %q", rec.Body.String())
+	}
+}
+
+func TestReloadKeepsMiddlewareAndHandlers(t *testing.T) {
+	r := New()
+	r.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			c.SetHeader("X-Mw", "1")
+			return next(c)
+		}
+	})
+	r.On404(func(c *Context) error { return c.String(404, "custom") })
+
+	r.Reload(func(r *Router) {
+		r.GET("/a", func(c *Context) error { return c.String(200, "a") })
+	})
+
+	req := httptest.NewRequest("GET", "/a", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Mw") != "1" {
+		t.Error("expected router middleware to apply to reloaded routes")
+	}
+
+	req = httptest.NewRequest("GET", "/missing", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "custom" {
+		t.Errorf("expected custom 404, got %q", rec.Body.String())
+	}
+}
+
+func TestReloadHostsAndGroups(t *testing.T) {
+	r := New()
+	r.Host("api.example.com").GET("/", func(c *Context) error { return c.String(200, "old host") })
+	r.Group("/admin").On404(func(c *Context) error { return c.String(404, "old admin") })
+
+	r.Reload(func(r *Router) {
+		r.Host("api.example.com").GET("/", func(c *Context) error { return c.String(200, "new host") })
+	})
+
+	req := httptest.NewRequest("GET", "http://api.example.com/", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "new host" {
+		t.Errorf("expected new host route, got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/admin/x", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() == "old admin" {
+		t.Error("expected group handlers of the old table to be dropped")
+	}
+}
+
+func TestReloadStaleHostGroup(t *testing.T) {
+	r := New()
+	api := r.Host("api.example.com")
+	api.GET("/", func(c *Context) error { return c.String(200, "old") })
+
+	r.Reload(func(r *Router) {
+		api.GET("/v2", func(c *Context) error { return c.String(200, "new") })
+	})
+
+	req := httptest.NewRequest("GET", "http://api.example.com/v2", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 || rec.Body.String() != "new" {
+		t.Errorf("expected route of a group created before the reload, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReloadMiddlewareDoesNotPileUp(t *testing.T) {
+	r := New()
+	for range 3 {
+		r.Reload(func(r *Router) {
+			r.Use(func(next Handler) Handler {
+				return func(c *Context) error {
+					c.Set("calls", c.GetInt("calls")+1)
+					return next(c)
+				}
+			})
+			r.GET("/", func(c *Context) error { return c.String(200, strconv.Itoa(c.GetInt("calls"))) })
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Body.String() != "1" {
+		t.Errorf("expected middleware of the last reload once, got %s calls", rec.Body.String())
+	}
+}
+
+func TestReloadPanicKeepsOldTable(t *testing.T) {
+	r := New()
+	r.GET("/old", func(c *Context) error { return c.String(200, "old") })
+
+	func() {
+		defer func() { _ = recover() }()
+		r.Reload(func(r *Router) {
+			r.GET("/new", listUsers)
+			panic("bad config")
+		})
+	}()
+
+	req := httptest.NewRequest("GET", "/old", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Errorf("expected old table after failed reload, got %d", rec.Code)
+	}
+
+	r.GET("/later", listUsers)
+	if len(r.Routes()) != 2 {
+		t.Errorf("expected registration into the old table, got %+v", r.Routes())
+	}
+}
+
+func TestReloadInFlightFinishesOnOldTable(t *testing.T) {
+	r := New()
+	started, release := make(chan struct{}), make(chan struct{})
+	r.GET("/slow/{id}", func(c *Context) error {
+		close(started)
+		<-release
+		return c.String(200, c.Param("id"))
+	})
+
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(rec, httptest.NewRequest("GET", "/slow/7", nil))
+	}()
+
+	<-started
+	r.Reload(func(r *Router) {})
+	close(release)
+	<-done
+
+	if rec.Code != 200 || rec.Body.String() != "7" {
+		t.Errorf("expected in-flight request to finish, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReloadConcurrentRequests(t *testing.T) {
+	r := New()
+	r.GET("/ping", func(c *Context) error { return c.String(200, "pong") })
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 200 {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+				if rec.Code != 200 {
+					t.Errorf("expected 200 during reload, got %d", rec.Code)
+					return
+				}
+			}
+		})
+	}
+
+	for range 50 {
+		r.Reload(func(r *Router) {
+			r.GET("/ping", func(c *Context) error { return c.String(200, "pong") })
+		})
+	}
+	wg.Wait()
+}
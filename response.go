@@ -1,6 +1,9 @@
 package mux
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 // ResponseWriter wraps http.ResponseWriter to track status and size
 type ResponseWriter struct {
@@ -31,3 +34,16 @@ func (r *ResponseWriter) Write(b []byte) (int, error) {
 	r.size += n
 	return n, err
 }
+
+// WriteString captures size and writes s without copying it when the
+// underlying writer supports io.StringWriter
+func (r *ResponseWriter) WriteString(s string) (int, error) {
+	n, err := io.WriteString(r.ResponseWriter, s)
+	r.size += n
+	return n, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter
+func (r *ResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
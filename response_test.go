@@ -70,3 +70,17 @@ func TestResponseWriterDefaultSize(t *testing.T) {
 		t.Errorf("expected default size 0, got %d", rw.Size())
 	}
 }
+
+func TestResponseWriterWriteString(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &ResponseWriter{ResponseWriter: rec}
+
+	w.WriteString("hello")
+
+	if w.Size() != 5 {
+		t.Errorf("expected size 5, got %d", w.Size())
+	}
+	if rec.Body.String() != "hello" {
+		t.Errorf("expected hello, got %s", rec.Body.String())
+	}
+}
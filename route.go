@@ -0,0 +1,173 @@
+package mux
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Route is a registered route returned by the registration methods
+type Route struct {
+	method     string
+	host       string
+	pattern    string
+	name       string
+	meta       map[string]any
+	tags       []string
+	handler    string
+	middleware []string
+
+	// API version of routes registered in a version group and the
+	// pattern with the version path segment
+	version  string
+	vpattern string
+
+	deprecation *deprecation
+
+	// OpenAPI declaration
+	api *api
+
+	// matcher and pattern the route is registered with
+	mux Matcher
+	key string
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method     string         `json:"method"`
+	Host       string         `json:"host,omitempty"`
+	Pattern    string         `json:"pattern"`
+	Version    string         `json:"version,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Meta       map[string]any `json:"meta,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Handler    string         `json:"handler"`
+	Middleware []string       `json:"middleware,omitempty"`
+
+	// Go types of the request and of the first response with content
+	// declared with Request and Response or inferred from Typed
+	Request  string `json:"request,omitempty"`
+	Response string `json:"response,omitempty"`
+
+	Deprecation *DeprecationInfo `json:"deprecation,omitempty"`
+}
+
+// Name sets the route name
+func (rt *Route) Name(name string) *Route {
+	rt.name = name
+	return rt
+}
+
+// Meta attaches a metadata value to the route
+func (rt *Route) Meta(key string, value any) *Route {
+	if rt.meta == nil {
+		rt.meta = make(map[string]any)
+	}
+	rt.meta[key] = value
+	return rt
+}
+
+// Tags adds tags to the route
+func (rt *Route) Tags(tags ...string) *Route {
+	rt.tags = append(rt.tags, tags...)
+	return rt
+}
+
+// Priority orders the route among overlapping wildcard routes, higher
+// first. Only matchers that support priorities, like NewTree, honor it;
+// on the default ServeMux and other matchers it does nothing.
+func (rt *Route) Priority(priority int) *Route {
+	if pm, ok := rt.mux.(priorityMatcher); ok {
+		pm.setPriority(rt.key, priority)
+	}
+	return rt
+}
+
+// Info returns a snapshot of the route
+func (rt *Route) Info() RouteInfo {
+	var req, res string
+	if a := rt.api; a != nil {
+		if a.request != nil {
+			req = a.request.String()
+		}
+		for _, r := range a.responses {
+			if r.typ != nil {
+				res = r.typ.String()
+				break
+			}
+		}
+	}
+
+	return RouteInfo{
+		Method:     rt.method,
+		Host:       rt.host,
+		Pattern:    rt.pattern,
+		Version:    rt.version,
+		Name:       rt.name,
+		Meta:       maps.Clone(rt.meta),
+		Tags:       slices.Clone(rt.tags),
+		Handler:    rt.handler,
+		Middleware: append([]string(nil), rt.middleware...),
+		Request:    req,
+		Response:   res,
+
+		Deprecation: rt.deprecation.info(),
+	}
+}
+
+// Routes returns the registered routes in registration order
+func (r *Router) Routes() []RouteInfo {
+	t := r.live.Load()
+	routes := make([]RouteInfo, len(t.routes))
+	for i, rt := range t.routes {
+		routes[i] = rt.Info()
+	}
+	return routes
+}
+
+// RoutesHandler returns a handler that serves the route table.
+// It responds with JSON unless the client asks for text/plain
+// or passes ?format=text.
+func (r *Router) RoutesHandler() Handler {
+	return func(c *Context) error {
+		routes := r.Routes()
+
+		if c.Query("format") != "text" && !strings.Contains(c.Header("Accept"), MIMETextPlain) {
+			return c.OK(routes)
+		}
+
+		var sb strings.Builder
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+		for _, rt := range routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				rt.Method, rt.Host+rt.Pattern, rt.Name, rt.Handler, strings.Join(rt.Middleware, ", "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, sb.String())
+	}
+}
+
+// funcName returns the short name of a function value
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -0,0 +1,273 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listUsers(c *Context) error {
+	return c.OK(nil)
+}
+
+func authRequired(next Handler) Handler {
+	return next
+}
+
+// -----------------------------------------------------------------------------
+// Routes
+// -----------------------------------------------------------------------------
+
+func TestRoutes(t *testing.T) {
+	r := New()
+	r.GET("/health", func(c *Context) error { return c.OK(nil) })
+	r.POST("/login", func(c *Context) error { return c.OK(nil) })
+
+	routes := r.Routes()
+
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+	if routes[0].Method != "GET" || routes[0].Pattern != "/health" {
+		t.Errorf("expected GET /health, got %s %s", routes[0].Method, routes[0].Pattern)
+	}
+	if routes[1].Method != "POST" || routes[1].Pattern != "/login" {
+		t.Errorf("expected POST /login, got %s %s", routes[1].Method, routes[1].Pattern)
+	}
+}
+
+func TestRoutesGroupPrefix(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/users/{id}", listUsers)
+
+	routes := r.Routes()
+
+	if len(routes) != 1 {
+		t.Fatalf("expected 1 route, got %d", len(routes))
+	}
+	if routes[0].Pattern != "/api/v1/users/{id}" {
+		t.Errorf("expected /api/v1/users/{id}, got %s", routes[0].Pattern)
+	}
+}
+
+func TestRoutesNameAndMeta(t *testing.T) {
+	r := New()
+	r.GET("/users", listUsers).Name("users.list").Meta("permission", "users:read")
+
+	routes := r.Routes()
+
+	if routes[0].Name != "users.list" {
+		t.Errorf("expected name users.list, got %s", routes[0].Name)
+	}
+	if routes[0].Meta["permission"] != "users:read" {
+		t.Errorf("expected permission users:read, got %v", routes[0].Meta["permission"])
+	}
+}
+
+func TestRoutesMetaIsCopy(t *testing.T) {
+	r := New()
+	r.GET("/users", listUsers).Meta("tier", "free")
+
+	routes := r.Routes()
+	routes[0].Meta["tier"] = "paid"
+
+	if r.Routes()[0].Meta["tier"] != "free" {
+		t.Error("expected Routes to return a copy of route metadata")
+	}
+}
+
+func TestRoutesHandlerName(t *testing.T) {
+	r := New()
+	r.GET("/users", listUsers)
+
+	routes := r.Routes()
+
+	if routes[0].Handler != "mux.listUsers" {
+		t.Errorf("expected handler mux.listUsers, got %s", routes[0].Handler)
+	}
+}
+
+func TestRoutesMiddlewareNames(t *testing.T) {
+	r := New()
+	r.Use(Logger())
+
+	api := r.Group("/api")
+	api.Use(authRequired)
+	api.GET("/users", listUsers)
+
+	routes := r.Routes()
+	mws := routes[0].Middleware
+
+	if len(mws) != 2 {
+		t.Fatalf("expected 2 middleware, got %v", mws)
+	}
+	if !strings.HasPrefix(mws[0], "mux.LoggerWith") {
+		t.Errorf("expected router middleware first, got %s", mws[0])
+	}
+	if mws[1] != "mux.authRequired" {
+		t.Errorf("expected mux.authRequired, got %s", mws[1])
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Routes Handler
+// -----------------------------------------------------------------------------
+
+func TestRoutesHandlerJSON(t *testing.T) {
+	r := New()
+	r.GET("/users", listUsers).Name("users.list")
+	r.GET("/debug/routes", r.RoutesHandler())
+
+	req := httptest.NewRequest("GET", "/debug/routes", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var routes []RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatalf("expected JSON body, got %v", err)
+	}
+	if len(routes) != 2 || routes[0].Name != "users.list" {
+		t.Errorf("unexpected route table: %+v", routes)
+	}
+}
+
+func TestRoutesHandlerText(t *testing.T) {
+	r := New()
+	r.GET("/users", listUsers)
+	r.GET("/debug/routes", r.RoutesHandler())
+
+	req := httptest.NewRequest("GET", "/debug/routes?format=text", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+
+	if ct := rec.Header().Get("Content-Type"); ct != MIMETextPlain {
+		t.Errorf("expected text/plain, got %s", ct)
+	}
+	if !strings.Contains(body, "METHOD") || !strings.Contains(body, "mux.listUsers") {
+		t.Errorf("unexpected route table: %s", body)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Deprecation
+// -----------------------------------------------------------------------------
+
+func TestRouteDeprecatedHeaders(t *testing.T) {
+	sunset := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New()
+	r.GET("/v1/users", listUsers).Deprecated(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), sunset, "/v2/users")
+
+	req := httptest.NewRequest("GET", "/v1/users", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected 200 before sunset, got %d", rec.Code)
+	}
+	if dep := rec.Header().Get("Deprecation"); dep != "@1748736000" {
+		t.Errorf("expected Deprecation @timestamp of the date, got %q", dep)
+	}
+	if s := rec.Header().Get("Sunset"); s != "Thu, 01 Jan 2099 00:00:00 GMT" {
+		t.Errorf("expected Sunset date, got %q", s)
+	}
+	if l := rec.Header().Get("Link"); l != `</v2/users>; rel="successor-version"` {
+		t.Errorf("expected successor Link, got %q", l)
+	}
+}
+
+func TestRouteDeprecatedCountsCalls(t *testing.T) {
+	r := New()
+	r.GET("/old", listUsers).Deprecated(time.Now(), time.Time{}, "")
+	r.GET("/new", listUsers)
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", "/old", nil))
+
+		if rec.Header().Get("Sunset") != "" || rec.Header().Get("Link") != "" {
+			t.Errorf("expected no Sunset or Link headers, got %v", rec.Header())
+		}
+	}
+
+	routes := r.Routes()
+	if routes[0].Deprecation == nil || routes[0].Deprecation.Calls != 3 {
+		t.Errorf("expected 3 counted calls, got %+v", routes[0].Deprecation)
+	}
+	if routes[1].Deprecation != nil {
+		t.Errorf("expected no deprecation on /new, got %+v", routes[1].Deprecation)
+	}
+}
+
+func TestRouteDeprecatedLogs(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+	r.GET("/old", listUsers).Deprecated(time.Now(), time.Time{}, "")
+
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/old", nil))
+	}
+
+	if n := strings.Count(buf.String(), "deprecated route called"); n != 1 {
+		t.Errorf("expected one logged call per interval, got %d:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), "route=/old calls=1") {
+		t.Errorf("expected route and call count, got %s", buf.String())
+	}
+}
+
+func TestRouteDeprecatedSunset(t *testing.T) {
+	r := New()
+	called := false
+	r.GET("/old", func(c *Context) error {
+		called = true
+		return c.OK(nil)
+	}).Deprecated(time.Now().Add(-24*time.Hour), time.Now().Add(-time.Hour), "/new")
+
+	req := httptest.NewRequest("GET", "/old", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 410 {
+		t.Errorf("expected 410 after sunset, got %d", rec.Code)
+	}
+	if called {
+		t.Error("expected handler not to run after sunset")
+	}
+	if rec.Header().Get("Link") == "" {
+		t.Error("expected successor Link on 410")
+	}
+}
+
+func TestRouteDeprecatedSunsetOnErr(t *testing.T) {
+	r := New()
+	r.OnErr(func(c *Context, err error) {
+		if errors.Is(err, ErrSunset) {
+			_ = c.String(410, "gone, see /new")
+			return
+		}
+		_ = c.String(500, "other")
+	})
+	r.GET("/old", listUsers).Deprecated(time.Now().Add(-24*time.Hour), time.Now().Add(-time.Hour), "/new")
+
+	req := httptest.NewRequest("GET", "/old", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 410 || rec.Body.String() != "gone, see /new" {
+		t.Errorf("expected custom 410 from OnErr, got %d %q", rec.Code, rec.Body.String())
+	}
+}
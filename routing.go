@@ -1,9 +1,15 @@
 package mux
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Handler handles HTTP requests
@@ -16,24 +22,94 @@ type Middleware func(next Handler) Handler
 // ErrorHandler processes handler errors
 type ErrorHandler func(c *Context, err error)
 
-// Router wraps http.ServeMux with error handling
+// Config configures a Router
+type Config struct {
+	// TrailingSlash policy. Default: TrailingSlashDefault
+	TrailingSlash TrailingSlash
+
+	// RedirectStatus used by TrailingSlashRedirect. Default: 301
+	RedirectStatus int
+
+	// CaseInsensitive matches literal path segments ignoring case
+	CaseInsensitive bool
+
+	// CleanPath collapses repeated slashes and resolves . and .. segments
+	// before matching instead of redirecting to the cleaned path
+	CleanPath bool
+
+	// Matcher returns a new matching engine for the router and each host.
+	// Default: http.NewServeMux
+	Matcher func() Matcher
+
+	// Versioning selects the API version of requests to version groups
+	Versioning VersionConfig
+
+	// Debug poisons Contexts when their handler returns, so a method
+	// called on a retained Context panics with the stack that acquired it
+	// instead of reading the state of another request. Released Contexts
+	// are not pooled. Enable it in tests; when disabled it costs a nil
+	// check per Context method call.
+	Debug bool
+
+	// ContextLocals mirrors locals into the request context.Context, so
+	// code given Context.Context can read them with Key and string keys
+	// and get the Context with FromContext. It adds a few allocations
+	// per request.
+	ContextLocals bool
+
+	// Logger is the base of Context.Logger. Default: slog.Default()
+	Logger *slog.Logger
+
+	// LogContext puts the logger attributes of a request in its
+	// context.Context, for loggers using NewLogHandler. It adds a few
+	// allocations per request.
+	LogContext bool
+}
+
+// Router wraps a Matcher, http.ServeMux by default, with error handling
 type Router struct {
+	cfg Config
 	ctx pool[Context]
-	mux *http.ServeMux
 	mws []Middleware
 
+	// table receiving registrations and the table serving requests,
+	// which differ while Reload builds a new one
+	*table
+	live      atomic.Pointer[table]
+	reload    sync.Mutex
+	reloading bool
+
 	// callbacks
-	on404 http.Handler
-	on405 http.Handler
-	onErr ErrorHandler
+	on404      http.Handler
+	on405      http.Handler
+	onErr      ErrorHandler
+	onGoErr    ErrorHandler
+	badVersion http.Handler
+	badAccept  http.Handler
 }
 
 // New creates a router
-func New() *Router {
+func New(config ...Config) *Router {
 
 	r := new(Router)
+	if len(config) > 0 {
+		r.cfg = config[0]
+	}
+	if r.cfg.RedirectStatus == 0 {
+		r.cfg.RedirectStatus = http.StatusMovedPermanently
+	}
+	if r.cfg.Matcher == nil {
+		r.cfg.Matcher = func() Matcher { return http.NewServeMux() }
+	}
+	if r.cfg.Versioning.Strategy == 0 {
+		r.cfg.Versioning.Strategy = VersionPath
+	}
+	if r.cfg.Versioning.Header == "" {
+		r.cfg.Versioning.Header = "API-Version"
+	}
 	r.ctx = pool[Context]{}
-	r.mux = http.NewServeMux()
+	r.table = r.newTable()
+	r.live.Store(r.table)
 
 	r.On404(func(c *Context) error {
 		return c.NotFound(M{"error": "not found"})
@@ -43,21 +119,42 @@ func New() *Router {
 		return c.MethodNotAllowed(M{"error": "method not allowed"})
 	})
 
+	r.badVersion = r.handler(func(c *Context) error {
+		return versionError(c, http.StatusBadRequest)
+	}, nil, nil)
+	r.badAccept = r.handler(func(c *Context) error {
+		return versionError(c, http.StatusNotAcceptable)
+	}, nil, nil)
+
 	r.OnErr(func(c *Context, err error) {
+		var he *HTTPError
+		if errors.As(err, &he) {
+			_ = c.JSON(he.Status, M{"error": he.Message})
+			return
+		}
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			_ = c.Problem(&Problem{Status: http.StatusBadRequest, Detail: "request is invalid", Errors: ve.Violations})
+			return
+		}
 		_ = c.InternalServerError(M{"error": "internal server error", "message": err.Error()})
 	})
 
+	r.OnGoErr(func(c *Context, err error) {
+		log.Printf("mux: background task of %s %s: %v", c.Method(), c.Path(), err)
+	})
+
 	return r
 }
 
 // On404 sets the handler for 404 responses
 func (r *Router) On404(h Handler) {
-	r.on404 = r.handler(h)
+	r.on404 = r.handler(h, nil, nil)
 }
 
 // On405 sets the handler for 405 responses
 func (r *Router) On405(h Handler) {
-	r.on405 = r.handler(h)
+	r.on405 = r.handler(h, nil, nil)
 }
 
 // OnErr sets the error handler
@@ -65,99 +162,315 @@ func (r *Router) OnErr(h ErrorHandler) {
 	r.onErr = h
 }
 
-// Use adds middleware to the router
+// OnGoErr sets the handler for errors and panics of Context.Go tasks.
+// It gets the Context copy of the task, whose responses are discarded.
+func (r *Router) OnGoErr(h ErrorHandler) {
+	r.onGoErr = h
+}
+
+// Use adds middleware to the router. Middleware added by a Reload
+// callback only wraps the routes of the table it builds.
 func (r *Router) Use(middlewares ...Middleware) {
+	if r.reloading {
+		r.table.mws = append(r.table.mws, middlewares...)
+		return
+	}
 	r.mws = append(r.mws, middlewares...)
 }
 
 // GET registers a handler for GET requests
-func (r *Router) GET(pattern string, h Handler) {
-	r.handle("GET", pattern, h)
+func (r *Router) GET(pattern string, h Handler) *Route {
+	return r.handle(nil, "GET", pattern, h)
 }
 
 // POST registers a handler for POST requests
-func (r *Router) POST(pattern string, h Handler) {
-	r.handle("POST", pattern, h)
+func (r *Router) POST(pattern string, h Handler) *Route {
+	return r.handle(nil, "POST", pattern, h)
 }
 
 // PUT registers a handler for PUT requests
-func (r *Router) PUT(pattern string, h Handler) {
-	r.handle("PUT", pattern, h)
+func (r *Router) PUT(pattern string, h Handler) *Route {
+	return r.handle(nil, "PUT", pattern, h)
 }
 
 // DELETE registers a handler for DELETE requests
-func (r *Router) DELETE(pattern string, h Handler) {
-	r.handle("DELETE", pattern, h)
+func (r *Router) DELETE(pattern string, h Handler) *Route {
+	return r.handle(nil, "DELETE", pattern, h)
 }
 
 // PATCH registers a handler for PATCH requests
-func (r *Router) PATCH(pattern string, h Handler) {
-	r.handle("PATCH", pattern, h)
+func (r *Router) PATCH(pattern string, h Handler) *Route {
+	return r.handle(nil, "PATCH", pattern, h)
 }
 
-// handle registers a handler for the method and path
-func (r *Router) handle(method, pattern string, h Handler) {
-	for i := len(r.mws) - 1; i >= 0; i-- {
-		h = r.mws[i](h)
+// Mount serves h for all methods under prefix with the prefix stripped
+func (r *Router) Mount(prefix string, h http.Handler) *Route {
+	rt := r.handle(nil, "", mountPattern(prefix), mountHandler(prefix, h))
+	rt.handler = handlerName(h)
+	return rt
+}
+
+// handle registers a handler for the method and path on behalf of the
+// group g, or the router when g is nil. Router middleware wraps the group
+// middleware mws, which wraps the handler.
+func (r *Router) handle(g *Group, method, pattern string, h Handler, mws ...Middleware) *Route {
+	mws = slices.Concat(r.mws, r.table.mws, mws)
+
+	t, mux := r.table, r.mux
+	if g != nil && g.host != nil {
+		// a host group created before Reload registers into the host
+		// routes of the current table
+		mux = r.host(g.host.pattern).mux
+	}
+
+	path, cons := r.routePath(mux, pattern)
+
+	rt := &Route{
+		method:  method,
+		pattern: pattern,
+		handler: funcName(h),
+	}
+	if info := typedInfoOf(h); info != nil {
+		rt.handler = info.handler
+		a := rt.openAPI()
+		a.request = info.req
+		a.responses = []apiResponse{{status: info.status, typ: info.res}}
+		a.inferred = true
+	}
+	if g != nil && g.version != "" {
+		rt.version = g.version
+		rt.vpattern = g.vprefix + pattern[len(g.prefix):]
+	}
+	for _, mw := range mws {
+		rt.middleware = append(rt.middleware, funcName(mw))
+	}
+
+	next := h
+	h = func(c *Context) error {
+		if rt.deprecation != nil {
+			return rt.deprecation.serve(c, next)
+		}
+		return next(c)
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	if g != nil && g.host != nil {
+		rt.host = g.host.pattern
+	}
+
+	hf := r.handler(h, g, rt)
+	rt.mux, rt.key = mux, routeKey(method, path)
+	if rt.version != "" {
+		r.handleVersion(t, mux, g, rt, cons, hf)
+	} else {
+		r.handlePattern(t, mux, g, rt.pattern, rt.key, "", cons, hf)
+	}
+	r.routes = append(r.routes, rt)
+
+	return rt
+}
+
+// routePath returns the pattern path registered with mux and the
+// constraints checked after the match, which are none when the matcher
+// evaluates them
+func (r *Router) routePath(mux Matcher, pattern string) (string, constraints) {
+	path, cons := pattern, constraints(nil)
+	if _, ok := mux.(constraintMatcher); !ok {
+		path, cons = parseConstraints(pattern)
+	}
+	if r.cfg.CaseInsensitive {
+		path = lowerLiterals(path)
 	}
-	r.mux.Handle(method+" "+pattern, r.handler(h))
+	return path, cons
 }
 
-// safelyHandleError calls the error handler with panic recovery
-func (r *Router) safelyHandleError(c *Context, err error) {
+// routeKey returns the matcher pattern for the method and path
+func routeKey(method, path string) string {
+	if method == "" {
+		return path
+	}
+	return method + " " + path
+}
+
+// safelyHandleError calls the nearest error handler of the group g
+// with panic recovery
+func (r *Router) safelyHandleError(c *Context, g *Group, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			log.Printf("mux: panic in error handler: %v", e)
 		}
 	}()
+	for ; g != nil; g = g.parent {
+		if g.onErr != nil {
+			g.onErr(c, err)
+			return
+		}
+	}
 	r.onErr(c, err)
 }
 
-// handler wraps a Handler into http.HandlerFunc with context pooling and panic recovery
-func (r *Router) handler(handlerFunc Handler) http.HandlerFunc {
+// handler wraps a Handler of the group g and route rt into
+// http.HandlerFunc with context pooling and panic recovery
+func (r *Router) handler(handlerFunc Handler, g *Group, rt *Route) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		// acquire context
-		c := r.ctx.get()
+		// take the context acquired by ServeHTTP, or acquire one
+		// when called directly
+		var c *Context
+		if rsp, ok := w.(*responder); ok {
+			c, w = rsp.take()
+		} else {
+			c = r.ctx.get()
+			if !r.cfg.Debug {
+				defer r.ctx.put(c)
+			}
+		}
 		c.attach(w, req)
+		c.group = g
+		c.route = rt
+		c.router = r
+		if r.cfg.Debug {
+			c.acquire()
+		}
+		if r.cfg.ContextLocals {
+			c.mirror()
+		}
+		if r.cfg.LogContext {
+			c.attachLog()
+		}
 
 		defer func() {
 			if err := recover(); err != nil {
-				r.safelyHandleError(c, fmt.Errorf("panic: %v", err))
+				r.safelyHandleError(c, c.group, fmt.Errorf("panic: %v", err))
 			}
 
 			// release context
+			if r.cfg.Debug {
+				c.poison()
+				return
+			}
 			c.detach()
-			r.ctx.put(c)
 		}()
 
 		// execute handler
 		if err := handlerFunc(c); err != nil {
-			r.safelyHandleError(c, err)
+			r.safelyHandleError(c, c.group, err)
 		}
 	}
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	h, p := r.mux.Handler(req)
+	// acquire context
+	c := r.ctx.get()
+	defer func() {
+		// release context, unless debug mode poisoned it
+		c.rsp.reset(nil, nil, false)
+		if !r.cfg.Debug {
+			r.ctx.put(c)
+		}
+	}()
+
+	t := r.live.Load()
+	vh := t.matchHost(req.Host)
 
-	if p != "" {
-		// Route matched. Delegate to mux which sets path values
-		// before calling the handler.
-		r.mux.ServeHTTP(w, req)
+	if r.cfg.CleanPath {
+		if cleanPath(req.URL.Path) != req.URL.Path {
+			req = withPath(req, cleanPath)
+		}
+	}
+
+	// The mux calls the matched route handler with the responder, which
+	// hands it the context. Mux redirects pass through under the default
+	// policy, and to a cleaned path also when trailing slashes match.
+	rsp := &c.rsp
+	policy := r.cfg.TrailingSlash
+	rsp.reset(c, w, policy == TrailingSlashDefault ||
+		policy == TrailingSlashMatch && cleanPath(req.URL.Path) != req.URL.Path)
+
+	// Routes of a matching host take precedence over the router routes
+	if vh != nil {
+		vh.mux.ServeHTTP(rsp, req)
+		if rsp.served {
+			return
+		}
+	}
+	t.mux.ServeHTTP(rsp, req)
+	if rsp.served {
 		return
 	}
 
-	// No route matched. The mux returns an internal handler that would
-	// write 404 or 405. used responder as a fake writer to capture the
-	// status without sending anything to the client, then substitute
-	// our custom error handler.
-	rsp := responder{ResponseWriter: w}
-	h.ServeHTTP(&rsp, req)
+	if r.canonical(t, rsp, req, vh) {
+		return
+	}
 
+	// No route matched. The responder recorded whether the mux would
+	// have written 404 or 405, substitute our custom error handler.
 	if rsp.status == http.StatusMethodNotAllowed {
-		r.on405.ServeHTTP(w, req)
+		if rsp.allow != "" {
+			w.Header().Set("Allow", rsp.allow)
+		}
+		if g := r.scope(t, vh, req.URL.Path, func(g *Group) bool { return g.on405 != nil }); g != nil {
+			g.on405.ServeHTTP(rsp, req)
+			return
+		}
+		r.on405.ServeHTTP(rsp, req)
 		return
 	}
 
+	if r.unknownVersion(t, req.URL.Path) {
+		r.badVersion.ServeHTTP(rsp, req)
+		return
+	}
+	r.notFound(t, rsp, req, vh)
+}
+
+// notFound serves the nearest 404 handler of the table t for the request
+func (r *Router) notFound(t *table, w http.ResponseWriter, req *http.Request, vh *host) {
+	if g := r.scope(t, vh, req.URL.Path, func(g *Group) bool { return g.on404 != nil }); g != nil {
+		g.on404.ServeHTTP(w, req)
+		return
+	}
 	r.on404.ServeHTTP(w, req)
 }
+
+// scope returns the most specific group of the table t covering the
+// request for which has reports true, preferring host groups over longer
+// prefixes
+func (r *Router) scope(t *table, vh *host, path string, has func(*Group) bool) *Group {
+	var best *Group
+	for _, g := range t.scopes {
+		if !has(g) || g.host != nil && (vh == nil || g.host.pattern != vh.pattern) || !matchPrefix(g.prefix, path, r.cfg.CaseInsensitive) {
+			continue
+		}
+		if best == nil || g.narrower(best) {
+			best = g
+		}
+	}
+	return best
+}
+
+// matchPrefix reports whether path is under the prefix pattern
+func matchPrefix(prefix, path string, fold bool) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	for prefix != "" {
+		var seg, want string
+		want, prefix = nextSegment(prefix)
+		if path == "" {
+			return false
+		}
+		seg, path = nextSegment(path)
+		if want != seg && !isWildcard(want) && !(fold && strings.EqualFold(want, seg)) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextSegment splits "/seg/rest" into "seg" and "/rest"
+func nextSegment(p string) (string, string) {
+	p = p[1:]
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		return p[:i], p[i:]
+	}
+	return p, ""
+}
@@ -3,7 +3,10 @@ package mux
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -292,6 +295,54 @@ func Test404vs405(t *testing.T) {
 	}
 }
 
+func Test405AllowHeader(t *testing.T) {
+	r := New()
+	r.GET("/resource", func(c *Context) error { return c.OK(nil) })
+	r.PUT("/resource", func(c *Context) error { return c.OK(nil) })
+
+	req := httptest.NewRequest("DELETE", "/resource", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	allow := rec.Header().Get("Allow")
+	if !strings.Contains(allow, "GET") || !strings.Contains(allow, "PUT") {
+		t.Errorf("expected Allow with GET and PUT, got %q", allow)
+	}
+}
+
+func Test404NoMuxHeaders(t *testing.T) {
+	r := New()
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != MIMEApplicationJSON {
+		t.Errorf("expected %s, got %s", MIMEApplicationJSON, ct)
+	}
+	if v := rec.Header().Get("X-Content-Type-Options"); v != "" {
+		t.Errorf("expected no mux headers, got X-Content-Type-Options=%s", v)
+	}
+}
+
+func TestHandlerRunsOncePerRequest(t *testing.T) {
+	r := New()
+
+	var calls int
+	r.GET("/users/{id}", func(c *Context) error {
+		calls++
+		return c.OK(nil)
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Error Handler
 // -----------------------------------------------------------------------------
@@ -317,6 +368,27 @@ func TestDefaultErrorHandler(t *testing.T) {
 	}
 }
 
+func TestDefaultErrorHandlerHTTPError(t *testing.T) {
+	r := New()
+	r.GET("/error", func(c *Context) error {
+		return fmt.Errorf("lookup: %w", &HTTPError{Status: 409, Message: "conflict"})
+	})
+
+	req := httptest.NewRequest("GET", "/error", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 409 {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["error"] != "conflict" {
+		t.Errorf("expected 'conflict', got %v", body["error"])
+	}
+}
+
 func TestCustomErrorHandler(t *testing.T) {
 	r := New()
 
@@ -446,6 +518,104 @@ func TestRouterMiddlewareOrder(t *testing.T) {
 	}
 }
 
+// -----------------------------------------------------------------------------
+// Mount
+// -----------------------------------------------------------------------------
+
+func TestRouterMount(t *testing.T) {
+	r := New()
+	r.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.URL.Path))
+	}))
+
+	req := httptest.NewRequest("GET", "/static/css/app.css", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "/css/app.css" {
+		t.Errorf("expected /css/app.css, got %s", rec.Body.String())
+	}
+}
+
+func TestRouterMountAllMethods(t *testing.T) {
+	r := New()
+	r.Mount("/std", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Method))
+	}))
+
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		req := httptest.NewRequest(method, "/std/x", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Body.String() != method {
+			t.Errorf("expected %s, got %s", method, rec.Body.String())
+		}
+	}
+}
+
+func TestRouterMountSubRouter(t *testing.T) {
+	sub := New()
+	sub.GET("/files/{name}", func(c *Context) error {
+		return c.OK(M{"tenant": c.Param("tenant"), "name": c.Param("name")})
+	})
+
+	r := New()
+	r.Mount("/tenants/{tenant}", sub)
+
+	req := httptest.NewRequest("GET", "/tenants/acme/files/report", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if body["tenant"] != "acme" {
+		t.Errorf("expected tenant=acme, got %v", body["tenant"])
+	}
+	if body["name"] != "report" {
+		t.Errorf("expected name=report, got %v", body["name"])
+	}
+}
+
+func TestRouterMountSubRouter404(t *testing.T) {
+	sub := New()
+	sub.On404(func(c *Context) error {
+		return c.NotFound(M{"error": "sub"})
+	})
+
+	r := New()
+	r.Mount("/sub", sub)
+
+	req := httptest.NewRequest("GET", "/sub/missing", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body M
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	if rec.Code != 404 || body["error"] != "sub" {
+		t.Errorf("expected sub-router 404, got %d %v", rec.Code, body)
+	}
+}
+
+func TestRouterMountRoute(t *testing.T) {
+	r := New()
+	r.Mount("/static/", http.FileServer(http.Dir(".")))
+
+	routes := r.Routes()
+
+	if routes[0].Pattern != "/static/" {
+		t.Errorf("expected /static/, got %s", routes[0].Pattern)
+	}
+	if routes[0].Handler != "*http.fileHandler" {
+		t.Errorf("expected *http.fileHandler, got %s", routes[0].Handler)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Panic Recovery
 // -----------------------------------------------------------------------------
@@ -666,3 +836,128 @@ func TestMultipleMethodsSamePath(t *testing.T) {
 		})
 	}
 }
+
+// -----------------------------------------------------------------------------
+// Allocations
+// -----------------------------------------------------------------------------
+
+func TestZeroAllocString(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	r := New()
+	r.GET("/ping", func(c *Context) error {
+		return c.String(200, "pong")
+	})
+
+	req := httptest.NewRequest("GET", "/ping", nil)
+	w := new(benchWriter)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.ServeHTTP(w, req)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations, got %v", allocs)
+	}
+}
+
+func TestZeroAllocQuery(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	r := New()
+	r.GET("/search", func(c *Context) error {
+		return c.String(200, c.Query("q", "none"))
+	})
+
+	req := httptest.NewRequest("GET", "/search?q=go&page=2", nil)
+	w := new(benchWriter)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.ServeHTTP(w, req)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations, got %v", allocs)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Benchmarks
+// -----------------------------------------------------------------------------
+
+// benchWriter is a reusable ResponseWriter that discards the body
+type benchWriter struct {
+	header http.Header
+}
+
+func (w *benchWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *benchWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *benchWriter) WriteHeader(int) {}
+
+func (w *benchWriter) WriteString(s string) (int, error) {
+	return len(s), nil
+}
+
+func benchmarkRouter() *Router {
+	r := New()
+	r.GET("/", func(c *Context) error { return c.Status(200) })
+	r.GET("/users/{id}", func(c *Context) error { return c.Status(200) })
+	r.POST("/users", func(c *Context) error { return c.Status(201) })
+	return r
+}
+
+func BenchmarkRouterHit(b *testing.B) {
+	r := benchmarkRouter()
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	w := new(benchWriter)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterString(b *testing.B) {
+	r := New()
+	r.GET("/ping", func(c *Context) error { return c.String(200, "pong") })
+	req := httptest.NewRequest("GET", "/ping", nil)
+	w := new(benchWriter)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterMiss(b *testing.B) {
+	r := benchmarkRouter()
+	r.On404(func(c *Context) error { return c.Status(404) })
+	req := httptest.NewRequest("GET", "/posts/42", nil)
+	w := new(benchWriter)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterMethodNotAllowed(b *testing.B) {
+	r := benchmarkRouter()
+	r.On405(func(c *Context) error { return c.Status(405) })
+	req := httptest.NewRequest("DELETE", "/users", nil)
+	w := new(benchWriter)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,419 @@
+package mux
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema as used by OpenAPI 3.1
+type Schema struct {
+	Ref         string     `json:"$ref,omitempty"`
+	Type        SchemaType `json:"type,omitempty"`
+	Format      string     `json:"format,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Enum        []any      `json:"enum,omitempty"`
+
+	// objects
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// arrays
+	Items       *Schema `json:"items,omitempty"`
+	MinItems    *int    `json:"minItems,omitempty"`
+	MaxItems    *int    `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	// strings
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// numbers
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	// composition
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+}
+
+// UnmarshalJSON also accepts the boolean schemas true and false, and the
+// nullable and boolean exclusiveMinimum and exclusiveMaximum of OpenAPI
+// 3.0. A nullable type gets "null" added to its type list.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{Not: &Schema{}}
+		return nil
+	}
+
+	type schema Schema
+	aux := struct {
+		*schema
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum"`
+		Nullable         bool            `json:"nullable"`
+	}{schema: (*schema)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Nullable && len(s.Type) > 0 && !s.Type.Has("null") {
+		s.Type = append(s.Type, "null")
+	}
+
+	var err error
+	s.ExclusiveMinimum, s.Minimum, err = exclusiveBound(aux.ExclusiveMinimum, s.Minimum)
+	if err != nil {
+		return err
+	}
+	s.ExclusiveMaximum, s.Maximum, err = exclusiveBound(aux.ExclusiveMaximum, s.Maximum)
+	return err
+}
+
+// exclusiveBound decodes an exclusive bound, which in OpenAPI 3.0 is a
+// boolean making the inclusive bound exclusive
+func exclusiveBound(data json.RawMessage, inclusive *float64) (exclusive, bound *float64, err error) {
+	switch string(data) {
+	case "", "null", "false":
+		return nil, inclusive, nil
+	case "true":
+		return inclusive, nil, nil
+	}
+	var n float64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, nil, err
+	}
+	return &n, inclusive, nil
+}
+
+// SchemaType is the type keyword, a single type or a list of types
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = SchemaType{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Has reports whether the type list contains typ
+func (t SchemaType) Has(typ string) bool {
+	for _, s := range t {
+		if s == typ {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[UUID]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaBuilder reflects JSON Schemas from Go types. Named struct types
+// are collected in defs and referenced by name.
+type schemaBuilder struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		defs:  make(map[string]*Schema),
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// schema returns the schema for values of type t
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: SchemaType{"string"}, Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: SchemaType{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaType{"integer"}, Minimum: ptr(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: SchemaType{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.define(t)}
+	}
+	return &Schema{}
+}
+
+// define adds the named struct type t to defs and returns its name
+func (b *schemaBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := schemaName(t.Name())
+	if _, taken := b.defs[name]; taken {
+		name = schemaName(t.String())
+	}
+	b.names[t] = name
+	b.defs[name] = nil // reserve the name for recursive types
+	b.defs[name] = b.object(t)
+	return name
+}
+
+// object returns the schema of the struct type t
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+	b.fields(t, s)
+	return s
+}
+
+// fields adds the JSON fields of the struct type t to s. Fields bound
+// from the path, query or headers are skipped.
+func (b *schemaBuilder) fields(t reflect.Type, s *Schema) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		opts = "," + opts + ","
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, s)
+				continue
+			}
+		}
+		name, ok := jsonName(f)
+		if !f.IsExported() || !ok || isParamField(f) {
+			continue
+		}
+
+		fs := b.schema(f.Type)
+		if strings.Contains(opts, ",string,") {
+			fs = &Schema{Type: SchemaType{"string"}}
+		}
+		if applyValidate(fs, f.Type, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		} else if f.Type.Kind() == reflect.Pointer && !strings.Contains(opts, ",omitempty,") && !strings.Contains(opts, ",omitzero,") {
+			fs = nullable(fs)
+		}
+		if doc := f.Tag.Get("doc"); doc != "" {
+			fs.Description = doc
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// jsonName returns the name encoding/json uses for the struct field f,
+// or false when it skips the field
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+// isParamField reports whether a request field is bound from the path,
+// query or headers instead of the body
+func isParamField(f reflect.StructField) bool {
+	return f.Tag.Get("path") != "" || f.Tag.Get("query") != "" || f.Tag.Get("header") != ""
+}
+
+var schemaNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// schemaName returns a component name for a Go type name
+func schemaName(s string) string {
+	return strings.Trim(schemaNameReplacer.ReplaceAllString(s, "_"), "_")
+}
+
+// nullable returns s allowing null, which a nil pointer field without
+// omitempty or omitzero encodes as
+func nullable(s *Schema) *Schema {
+	switch {
+	case len(s.Type) > 0:
+		s.Type = append(s.Type, "null")
+		return s
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: SchemaType{"null"}}}}
+	}
+	// an empty schema already allows null
+	return s
+}
+
+// applyValidate adds the constraints of a validate tag to s and reports
+// whether the field is required. Rules after dive apply to the items.
+func applyValidate(s *Schema, t reflect.Type, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if s.Items != nil {
+				applyValidate(s.Items, t.Elem(), strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			applyBound(s, t, name, param)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(t, v))
+			}
+		case "email":
+			s.Format = "email"
+		case "url", "uri", "http_url":
+			s.Format = "uri"
+		case "uuid", "uuid4", "uuid_rfc4122", "uuid4_rfc4122":
+			s.Format = "uuid"
+		case "ipv4", "ipv6", "hostname":
+			s.Format = name
+		case "datetime":
+			s.Format = "date-time"
+		case "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			s.Pattern = `^[-+]?[0-9]+(?:\.[0-9]+)?$`
+		case "unique":
+			s.UniqueItems = true
+		}
+	}
+	return required
+}
+
+// applyBound adds a min, max, len, gt, gte, lt or lte rule to s as a
+// length, item count or numeric bound depending on the kind of t
+func applyBound(s *Schema, t reflect.Type, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array:
+		count := int(n)
+		switch rule {
+		case "gt":
+			count++
+		case "lt":
+			count--
+		}
+		lo, hi := &s.MinLength, &s.MaxLength
+		if t.Kind() != reflect.String {
+			lo, hi = &s.MinItems, &s.MaxItems
+		}
+		switch rule {
+		case "min", "gt", "gte":
+			*lo = ptr(count)
+		case "max", "lt", "lte":
+			*hi = ptr(count)
+		case "len":
+			*lo, *hi = ptr(count), ptr(count)
+		}
+	case reflect.Map, reflect.Struct, reflect.Bool:
+	default:
+		switch rule {
+		case "min", "gte":
+			s.Minimum = ptr(n)
+		case "max", "lte":
+			s.Maximum = ptr(n)
+		case "gt":
+			s.ExclusiveMinimum = ptr(n)
+		case "lt":
+			s.ExclusiveMaximum = ptr(n)
+		}
+	}
+}
+
+// enumValue converts a oneof value to the JSON type of t
+func enumValue(t reflect.Type, v string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -5,17 +5,92 @@ import (
 	"sync"
 )
 
+// responder is the writer Router.ServeHTTP passes to the mux. Route
+// handlers take the pooled Context and the client writer from it, so
+// each request is matched once. When no route matches, it records the
+// status written by the mux instead of sending it.
 type responder struct {
-	status int
 	http.ResponseWriter
+	c *Context
+
+	// served is set once a handler or the mux wrote the response
+	served bool
+
+	// redirect passes mux redirects through to the client
+	redirect bool
+
+	// miss status and allowed methods recorded from the mux
+	status int
+	allow  string
+	header http.Header
+}
+
+// reset prepares the responder for a request
+func (w *responder) reset(c *Context, rw http.ResponseWriter, redirect bool) {
+	w.ResponseWriter = rw
+	w.c = c
+	w.served = false
+	w.redirect = redirect
+	w.status = 0
+	w.allow = ""
+}
+
+// take marks the request served and returns the Context and client writer
+func (w *responder) take() (*Context, http.ResponseWriter) {
+	w.served = true
+	return w.c, w.ResponseWriter
+}
+
+// clientHeader returns the header of the response to the client, which
+// a responder only writes once a route takes the request
+func clientHeader(w http.ResponseWriter) http.Header {
+	if rsp, ok := w.(*responder); ok {
+		return rsp.ResponseWriter.Header()
+	}
+	return w.Header()
+}
+
+func (w *responder) Header() http.Header {
+	if w.served {
+		return w.ResponseWriter.Header()
+	}
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
 }
 
 func (w *responder) WriteHeader(status int) {
-	w.status = status
+	if w.served {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if w.redirect && status >= 300 && status < 400 {
+		// mux redirect to a canonical path
+		for k, v := range w.header {
+			w.ResponseWriter.Header()[k] = v
+		}
+		clear(w.header)
+		w.served = true
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if status == http.StatusMethodNotAllowed {
+		w.allow = w.header.Get("Allow")
+	}
+	if w.status != http.StatusMethodNotAllowed {
+		w.status = status
+	}
+	clear(w.header)
 }
 
-func (w *responder) Write([]byte) (int, error) {
-	return 0, nil // discard body
+func (w *responder) Write(b []byte) (int, error) {
+	if w.served {
+		return w.ResponseWriter.Write(b)
+	}
+	return len(b), nil // discard body
 }
 
 type pool[T any] struct {
@@ -0,0 +1,508 @@
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Matcher matches requests to registered handlers. It is implemented by
+// http.ServeMux, the default, and by the radix tree returned by NewTree.
+// Patterns use the http.ServeMux syntax. When no handler matches, the
+// handler returned by Handler writes 404, or 405 with an Allow header,
+// and the pattern is empty.
+type Matcher interface {
+	http.Handler
+	Handle(pattern string, handler http.Handler)
+	Handler(req *http.Request) (h http.Handler, pattern string)
+}
+
+// constraintMatcher is implemented by matchers that evaluate
+// {name:constraint} wildcards themselves. For other matchers the router
+// strips constraints and checks them after the match.
+type constraintMatcher interface {
+	matchesConstraints()
+}
+
+// priorityMatcher is implemented by matchers that order wildcard routes
+// by priority
+type priorityMatcher interface {
+	setPriority(pattern string, priority int)
+}
+
+// nodeKind is the kind of a tree node
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	catchAllNode
+)
+
+// tree is a radix tree Matcher. Static text is stored in compressed
+// nodes, wildcards in child nodes tried after the static ones. Besides
+// the http.ServeMux syntax it supports {name:constraint} wildcards,
+// several wildcards in one segment such as /files/{name}.{ext}, and
+// route priorities that order overlapping wildcard routes.
+type tree struct {
+	mu     sync.RWMutex
+	root   *node
+	leaves map[string]*leaf
+	seq    int
+
+	matches pool[match]
+}
+
+type node struct {
+	kind   nodeKind
+	prefix string
+	parent *node
+
+	// static children with their first bytes in indices
+	indices  string
+	static   []*node
+	wildcard []*node
+
+	// wildcard constraint
+	expr  string
+	match func(string) bool
+
+	priority int
+	seq      int
+
+	leaf *leaf
+}
+
+// leaf holds the handlers of a path pattern by method
+type leaf struct {
+	path     string
+	names    []string
+	nodes    []*node
+	handlers map[string]http.Handler
+	patterns map[string]string
+}
+
+// match is the result of a tree lookup
+type match struct {
+	method  string
+	handler http.Handler
+	pattern string
+	leaf    *leaf
+	values  []string
+	allow   []string
+	buf     [8]string
+
+	// the path kept slashes and percent signs of segments escaped, and
+	// so do the values
+	escaped bool
+}
+
+// NewTree returns a radix tree Matcher
+func NewTree() Matcher {
+	return &tree{
+		root:   &node{},
+		leaves: make(map[string]*leaf),
+	}
+}
+
+func (t *tree) matchesConstraints() {}
+
+// Handle registers the handler for the pattern
+func (t *tree) Handle(pattern string, handler http.Handler) {
+	method, path := "", pattern
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 && !strings.HasPrefix(pattern, "/") {
+		method, path = pattern[:i], strings.TrimLeft(pattern[i:], " \t")
+	}
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("mux: pattern %q: host patterns are not supported by the tree matcher", pattern))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := path
+	if strings.HasSuffix(key, "/{$}") {
+		key = strings.TrimSuffix(key, "{$}")
+	} else if strings.HasSuffix(key, "/") {
+		// trailing slash matches the subtree
+		key += "{...}"
+	}
+
+	l := t.leaves[key]
+	if l == nil {
+		l = t.insert(pattern, key)
+		t.leaves[key] = l
+	}
+	if _, ok := l.handlers[method]; ok {
+		panic(fmt.Sprintf("mux: pattern %q conflicts with %q", pattern, l.patterns[method]))
+	}
+	l.handlers[method] = handler
+	l.patterns[method] = pattern
+}
+
+// insert adds the nodes of the path pattern key and returns its leaf
+func (t *tree) insert(pattern, key string) *leaf {
+	l := &leaf{
+		path:     key,
+		handlers: make(map[string]http.Handler),
+		patterns: make(map[string]string),
+	}
+
+	n := t.root
+	for key != "" {
+		i := strings.IndexByte(key, '{')
+		if i != 0 {
+			if i < 0 {
+				i = len(key)
+			}
+			n = n.insertStatic(key[:i])
+			key = key[i:]
+			continue
+		}
+
+		end := closingBrace(key, 0)
+		if end < 0 {
+			panic(fmt.Sprintf("mux: pattern %q: missing closing brace", pattern))
+		}
+		name, expr, _ := strings.Cut(key[1:end], ":")
+		key = key[end+1:]
+
+		kind := paramNode
+		if strings.HasSuffix(name, "...") {
+			kind = catchAllNode
+			name = strings.TrimSuffix(name, "...")
+			if key != "" {
+				panic(fmt.Sprintf("mux: pattern %q: %s... wildcard must be at the end", pattern, name))
+			}
+		}
+		if n.kind != staticNode && n != t.root {
+			panic(fmt.Sprintf("mux: pattern %q: adjacent wildcards are not supported", pattern))
+		}
+
+		n = n.insertWildcard(kind, expr, t.seq)
+		t.seq++
+		l.names = append(l.names, name)
+		l.nodes = append(l.nodes, n)
+	}
+
+	n.leaf = l
+	return l
+}
+
+// insertStatic adds the static text s below n and returns its node
+func (n *node) insertStatic(s string) *node {
+	for {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &node{prefix: s, parent: n}
+			n.indices += s[:1]
+			n.static = append(n.static, child)
+			return child
+		}
+
+		child := n.static[i]
+		common := 0
+		for common < len(s) && common < len(child.prefix) && s[common] == child.prefix[common] {
+			common++
+		}
+
+		if common < len(child.prefix) {
+			// split the child at the common prefix
+			split := &node{prefix: child.prefix[:common], parent: n}
+			child.prefix = child.prefix[common:]
+			child.parent = split
+			split.indices = child.prefix[:1]
+			split.static = []*node{child}
+			n.static[i] = split
+			child = split
+		}
+
+		if common == len(s) {
+			return child
+		}
+		n, s = child, s[common:]
+	}
+}
+
+// insertWildcard adds a wildcard child to n and returns it
+func (n *node) insertWildcard(kind nodeKind, expr string, seq int) *node {
+	for _, child := range n.wildcard {
+		if child.kind == kind && child.expr == expr {
+			return child
+		}
+	}
+
+	child := &node{kind: kind, parent: n, expr: expr, seq: seq}
+	if expr != "" {
+		child.match = constraintFunc(expr)
+	}
+	n.wildcard = append(n.wildcard, child)
+	n.sortWildcards()
+	return child
+}
+
+// sortWildcards orders wildcard children by priority, then constrained
+// before plain wildcards before catch-all, then registration order
+func (n *node) sortWildcards() {
+	rank := func(c *node) int {
+		switch {
+		case c.kind == catchAllNode:
+			return 2
+		case c.expr == "":
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(n.wildcard, func(i, j int) bool {
+		a, b := n.wildcard[i], n.wildcard[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a.seq < b.seq
+	})
+}
+
+// setPriority raises the priority of the wildcards of a pattern
+func (t *tree) setPriority(pattern string, priority int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, l := range t.leaves {
+		for _, p := range l.patterns {
+			if p != pattern {
+				continue
+			}
+			for _, n := range l.nodes {
+				if priority > n.priority {
+					n.priority = priority
+					n.parent.sortWildcards()
+				}
+			}
+		}
+	}
+}
+
+// lookup finds the handler for the method and path
+func (t *tree) lookup(method, path string, m *match) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	m.method = method
+	m.values = m.buf[:0]
+	t.root.search(path, m)
+}
+
+// search matches the remaining path below n
+func (n *node) search(path string, m *match) bool {
+	if path == "" && n.leaf != nil && m.try(n.leaf) {
+		return true
+	}
+
+	if path != "" {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.static[i]
+			if strings.HasPrefix(path, child.prefix) && child.search(path[len(child.prefix):], m) {
+				return true
+			}
+		}
+	}
+
+	for _, child := range n.wildcard {
+		if child.kind == catchAllNode {
+			if child.match != nil && !child.match(path) {
+				continue
+			}
+			m.values = append(m.values, path)
+			if child.leaf != nil && m.try(child.leaf) {
+				return true
+			}
+			m.values = m.values[:len(m.values)-1]
+			continue
+		}
+
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		// try the longest value first, shorter ones only where static
+		// text in the same segment can follow
+		for k := end; k > 0; k-- {
+			if k < end && strings.IndexByte(child.indices, path[k]) < 0 {
+				continue
+			}
+			if child.match != nil && !child.match(path[:k]) {
+				continue
+			}
+			m.values = append(m.values, path[:k])
+			if child.search(path[k:], m) {
+				return true
+			}
+			m.values = m.values[:len(m.values)-1]
+		}
+	}
+
+	return false
+}
+
+// try selects the handler of l for the method, recording the allowed
+// methods when there is none
+func (m *match) try(l *leaf) bool {
+	method := m.method
+	h, ok := l.handlers[method]
+	if !ok && method == "HEAD" {
+		method = "GET"
+		h, ok = l.handlers[method]
+	}
+	if !ok {
+		method = ""
+		h, ok = l.handlers[method]
+	}
+	if ok {
+		m.handler = h
+		m.pattern = l.patterns[method]
+		m.leaf = l
+		return true
+	}
+
+	for method := range l.handlers {
+		if !slices.Contains(m.allow, method) {
+			m.allow = append(m.allow, method)
+		}
+		if method == "GET" && !slices.Contains(m.allow, "HEAD") {
+			m.allow = append(m.allow, "HEAD")
+		}
+	}
+	return false
+}
+
+// Handler returns the handler for the request and its pattern
+func (t *tree) Handler(req *http.Request) (http.Handler, string) {
+	m := t.matches.get()
+	defer t.release(m)
+
+	t.handler(req, m)
+	return m.handler, m.pattern
+}
+
+// handler matches the request into m. When no route matches, m holds a
+// redirect, 404 or 405 handler and no leaf.
+func (t *tree) handler(req *http.Request, m *match) {
+	path, escaped := matchPath(req.URL)
+	m.escaped = escaped
+
+	if req.Method != "CONNECT" {
+		if clean := cleanPath(path); clean != path {
+			// like ServeMux, report the pattern the clean path matches
+			m2 := t.matches.get()
+			defer t.release(m2)
+			t.lookup(req.Method, clean, m2)
+			m.handler, m.pattern = redirect(req, clean, escaped), m2.pattern
+			return
+		}
+	}
+
+	t.lookup(req.Method, path, m)
+	if m.leaf != nil {
+		return
+	}
+
+	if len(m.allow) == 0 && !strings.HasSuffix(path, "/") {
+		// redirect /tree to a registered /tree/, which is a trailing
+		// catch-all that would match the empty rest
+		m2 := t.matches.get()
+		defer t.release(m2)
+		t.lookup(req.Method, path+"/", m2)
+		if l := m2.leaf; l != nil && l.nodes[len(l.nodes)-1].kind == catchAllNode && m2.values[len(m2.values)-1] == "" {
+			m.handler, m.pattern = redirect(req, path+"/", escaped), m2.pattern
+			return
+		}
+	}
+
+	if len(m.allow) > 0 {
+		slices.Sort(m.allow)
+		allow := strings.Join(m.allow, ", ")
+		m.handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Allow", allow)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		})
+		return
+	}
+	m.handler = http.NotFoundHandler()
+}
+
+// segmentEscaper and segmentUnescaper escape the slashes and percent
+// signs of a path segment
+var (
+	segmentEscaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	segmentUnescaper = strings.NewReplacer("%25", "%", "%2F", "/")
+)
+
+// matchPath returns the path to match for u and whether it escapes
+// slashes and percent signs decoded within a segment, so that, like
+// ServeMux, an encoded slash does not separate segments
+func matchPath(u *url.URL) (string, bool) {
+	if u.RawPath == "" {
+		return u.Path, false
+	}
+	raw := u.EscapedPath()
+	if !strings.Contains(raw, "%2F") && !strings.Contains(raw, "%2f") {
+		return u.Path, false
+	}
+
+	var sb strings.Builder
+	for i, seg := range strings.Split(raw, "/") {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		s, err := url.PathUnescape(seg)
+		if err != nil {
+			return u.Path, false
+		}
+		sb.WriteString(segmentEscaper.Replace(s))
+	}
+	return sb.String(), true
+}
+
+// redirect returns a handler redirecting to the match path p
+func redirect(req *http.Request, p string, escaped bool) http.Handler {
+	u := url.URL{Path: p, RawQuery: req.URL.RawQuery}
+	if escaped {
+		u.Path, u.RawPath = segmentUnescaper.Replace(p), p
+	}
+	return http.RedirectHandler(u.String(), http.StatusTemporaryRedirect)
+}
+
+// ServeHTTP dispatches the request to the matching handler
+func (t *tree) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m := t.matches.get()
+	defer t.release(m)
+
+	t.handler(req, m)
+	if m.leaf != nil {
+		req.Pattern = m.pattern
+		for i, name := range m.leaf.names {
+			if name == "" {
+				continue
+			}
+			v := m.values[i]
+			if m.escaped {
+				v = segmentUnescaper.Replace(v)
+			}
+			req.SetPathValue(name, v)
+		}
+	}
+	m.handler.ServeHTTP(w, req)
+}
+
+// release resets m and returns it to the pool
+func (t *tree) release(m *match) {
+	*m = match{allow: m.allow[:0]}
+	t.matches.put(m)
+}
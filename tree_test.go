@@ -0,0 +1,321 @@
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// engines are the matchers the conformance suite runs against
+var engines = map[string]func() Matcher{
+	"ServeMux": nil,
+	"Tree":     NewTree,
+}
+
+func echoParams(names ...string) Handler {
+	return func(c *Context) error {
+		vals := make([]string, len(names))
+		for i, name := range names {
+			vals[i] = name + "=" + c.Param(name)
+		}
+		return c.String(200, strings.Join(vals, " "))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Conformance
+// -----------------------------------------------------------------------------
+
+func TestMatcherConformance(t *testing.T) {
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+		header       string
+	}{
+		{"GET", "/", 200, "root", ""},
+		{"GET", "/users", 200, "list", ""},
+		{"GET", "/users/me", 200, "me", ""},
+		{"GET", "/users/42", 200, "id=42", ""},
+		{"GET", "/users/42/posts/7", 200, "id=42 post=7", ""},
+		{"GET", "/files/a/b/c.txt", 200, "path=a/b/c.txt", ""},
+		{"GET", "/files/", 200, "path=", ""},
+		{"GET", "/static/css/site.css", 200, "static", ""},
+		{"GET", "/static/", 200, "static", ""},
+		{"GET", "/static", 307, "", "/static/"},
+		{"GET", "/exact/", 200, "exact", ""},
+		{"GET", "/exact/more", 404, "", ""},
+		{"GET", "/items/7", 200, "item=7", ""},
+		{"GET", "/items/abc", 404, "", ""},
+		{"HEAD", "/users", 200, "", ""},
+		{"POST", "/users", 201, "created", ""},
+		{"DELETE", "/users", 405, "", "GET, HEAD, POST"},
+		{"PUT", "/any/thing", 200, "any", ""},
+		{"GET", "/missing", 404, "", ""},
+		{"GET", "/users//42", 307, "", "/users/42"},
+		{"GET", "/users/a%2Fb", 200, "id=a/b", ""},
+		{"GET", "/files/a%2Fb/c%25d", 200, "path=a/b/c%d", ""},
+		{"GET", "/files", 307, "", "/files/"},
+	}
+
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			r := New(Config{Matcher: engine})
+			r.GET("/{$}", func(c *Context) error { return c.String(200, "root") })
+			r.GET("/users", func(c *Context) error { return c.String(200, "list") })
+			r.POST("/users", func(c *Context) error { return c.String(201, "created") })
+			r.GET("/users/me", func(c *Context) error { return c.String(200, "me") })
+			r.GET("/users/{id}", echoParams("id"))
+			r.GET("/users/{id}/posts/{post}", echoParams("id", "post"))
+			r.GET("/files/{path...}", echoParams("path"))
+			r.GET("/static/", func(c *Context) error { return c.String(200, "static") })
+			r.GET("/exact/{$}", func(c *Context) error { return c.String(200, "exact") })
+			r.GET("/items/{item:int}", echoParams("item"))
+			r.Mount("/any", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte("any"))
+			}))
+
+			for _, tt := range tests {
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				if rec.Code != tt.code {
+					t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.code, rec.Code)
+					continue
+				}
+				if tt.body != "" && rec.Body.String() != tt.body {
+					t.Errorf("%s %s: expected body %q, got %q", tt.method, tt.path, tt.body, rec.Body.String())
+				}
+				switch {
+				case tt.code == 405 && rec.Header().Get("Allow") != tt.header:
+					t.Errorf("%s %s: expected Allow %q, got %q", tt.method, tt.path, tt.header, rec.Header().Get("Allow"))
+				case tt.code == 307 && rec.Header().Get("Location") != tt.header:
+					t.Errorf("%s %s: expected Location %q, got %q", tt.method, tt.path, tt.header, rec.Header().Get("Location"))
+				}
+			}
+		})
+	}
+}
+
+func TestMatcherConformanceHostsAndGroups(t *testing.T) {
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			r := New(Config{Matcher: engine})
+			r.Host("{tenant}.example.com").GET("/", echoParams("tenant"))
+			api := r.Group("/api")
+			api.On404(func(c *Context) error { return c.String(404, "api 404") })
+			api.GET("/ping", func(c *Context) error { return c.String(200, "pong") })
+
+			req := httptest.NewRequest("GET", "http://acme.example.com/", nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Body.String() != "tenant=acme" {
+				t.Errorf("expected tenant=acme, got %q", rec.Body.String())
+			}
+
+			req = httptest.NewRequest("GET", "/api/nope", nil)
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != 404 || rec.Body.String() != "api 404" {
+				t.Errorf("expected group 404, got %d %q", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMatcherConformancePolicies(t *testing.T) {
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			r := New(Config{Matcher: engine, TrailingSlash: TrailingSlashMatch, CaseInsensitive: true})
+			r.GET("/Users/{id}", echoParams("id"))
+
+			for _, path := range []string{"/users/AbC", "/USERS/AbC/"} {
+				req := httptest.NewRequest("GET", path, nil)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				if rec.Code != 200 || rec.Body.String() != "id=AbC" {
+					t.Errorf("%s: expected id=AbC, got %d %q", path, rec.Code, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMatcherConformanceConflict(t *testing.T) {
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic for duplicate route")
+				}
+			}()
+			r := New(Config{Matcher: engine})
+			r.GET("/users/{id}", listUsers)
+			r.GET("/users/{id}", listUsers)
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tree
+// -----------------------------------------------------------------------------
+
+func TestTreeMidSegmentWildcards(t *testing.T) {
+	r := New(Config{Matcher: NewTree})
+	r.GET("/files/{name}.{ext}", echoParams("name", "ext"))
+	r.GET("/img/v{version:int}-{slug}", echoParams("version", "slug"))
+
+	tests := []struct {
+		path, body string
+	}{
+		{"/files/report.pdf", "name=report ext=pdf"},
+		{"/files/archive.tar.gz", "name=archive.tar ext=gz"},
+		{"/img/v2-logo", "version=2 slug=logo"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %d %q", tt.path, tt.body, rec.Code, rec.Body.String())
+		}
+	}
+
+	for _, path := range []string{"/files/README", "/img/vx-logo"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 404 {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestTreeRegexSegments(t *testing.T) {
+	r := New(Config{Matcher: NewTree})
+	r.GET("/users/{id:int}", func(c *Context) error { return c.String(200, "by id") })
+	r.GET("/users/{name:[a-z]+}", func(c *Context) error { return c.String(200, "by name") })
+	r.GET("/users/{other}", func(c *Context) error { return c.String(200, "other") })
+
+	tests := []struct {
+		path, body string
+	}{
+		{"/users/42", "by id"},
+		{"/users/alice", "by name"},
+		{"/users/Alice", "other"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestTreeBacktracking(t *testing.T) {
+	r := New(Config{Matcher: NewTree})
+	r.GET("/a/{x}/c", func(c *Context) error { return c.String(200, "param") })
+	r.GET("/a/b/d", func(c *Context) error { return c.String(200, "static") })
+
+	req := httptest.NewRequest("GET", "/a/b/c", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "param" {
+		t.Errorf("expected param route after static miss, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTreePriority(t *testing.T) {
+	serve := func(priority int) string {
+		r := New(Config{Matcher: NewTree})
+		r.GET("/docs/{page}", func(c *Context) error { return c.String(200, "page") })
+		r.GET("/docs/{path...}", func(c *Context) error { return c.String(200, "catch-all") }).Priority(priority)
+
+		req := httptest.NewRequest("GET", "/docs/intro", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	if body := serve(0); body != "page" {
+		t.Errorf("expected wildcard before catch-all, got %q", body)
+	}
+	if body := serve(10); body != "catch-all" {
+		t.Errorf("expected higher priority route first, got %q", body)
+	}
+}
+
+func TestTreeMethodFallsThrough(t *testing.T) {
+	r := New(Config{Matcher: NewTree})
+	r.POST("/users/{id:int}", func(c *Context) error { return c.String(200, "post") })
+	r.GET("/users/{id}", func(c *Context) error { return c.String(200, "get") })
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "get" {
+		t.Errorf("expected less specific route with matching method, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTreeHostPatternPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for host pattern")
+		}
+	}()
+	NewTree().Handle("example.com/", http.NotFoundHandler())
+}
+
+func TestTreeRoutePattern(t *testing.T) {
+	r := New(Config{Matcher: NewTree})
+	r.GET("/users/{id:int}", func(c *Context) error {
+		return c.String(200, c.r.Pattern)
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "GET /users/{id:int}" {
+		t.Errorf("expected matched pattern, got %q", rec.Body.String())
+	}
+}
+
+func TestTreeCleanPathRedirectPattern(t *testing.T) {
+	h := http.NotFoundHandler()
+	for name, newMatcher := range map[string]func() Matcher{"ServeMux": func() Matcher { return http.NewServeMux() }, "Tree": NewTree} {
+		m := newMatcher()
+		m.Handle("GET /users/{id}", h)
+
+		_, pattern := m.Handler(httptest.NewRequest("GET", "/users//42", nil))
+		if pattern != "GET /users/{id}" {
+			t.Errorf("%s: expected the pattern of the clean path, got %q", name, pattern)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Benchmarks
+// -----------------------------------------------------------------------------
+
+func BenchmarkTreeHit(b *testing.B) {
+	r := New(Config{Matcher: NewTree})
+	r.GET("/users/{id}", func(c *Context) error { return c.String(200, "ok") })
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	w := &benchWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,87 @@
+package mux
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// StatusCoder is implemented by results of typed handlers that choose
+// their response status. Results without it are 200 OK.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// typedInfo describes a typed handler to the route it is registered on
+type typedInfo struct {
+	req, res reflect.Type
+	status   int
+	handler  string
+}
+
+// typedHandlers maps the closures Typed returns to their types
+var typedHandlers sync.Map // closure -> *typedInfo
+
+// closure returns the closure a func value points to, which is distinct
+// for each call of Typed
+func closure(h Handler) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&h))
+}
+
+// typedInfoOf returns the types of a handler returned by Typed, or nil
+func typedInfoOf(h Handler) *typedInfo {
+	if info, ok := typedHandlers.Load(closure(h)); ok {
+		return info.(*typedInfo)
+	}
+	return nil
+}
+
+// Typed adapts fn into a Handler. The request is bound into Req with
+// BindRequest, which validates it, and the result is rendered with
+// content negotiation by Render, with the status of a StatusCoder result.
+// A 204 or 304 status is written without a body. Routes registered with
+// the returned handler declare Req and Res for introspection and the
+// OpenAPI document; wrapping it in middleware first drops them.
+func Typed[Req, Res any](fn func(c *Context, in Req) (Res, error)) Handler {
+	h := Handler(func(c *Context) error {
+		var in Req
+		if err := c.BindRequest(&in); err != nil {
+			return err
+		}
+		out, err := fn(c, in)
+		if err != nil {
+			return err
+		}
+
+		status := http.StatusOK
+		if sc, ok := any(out).(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+		if status == http.StatusNoContent || status == http.StatusNotModified {
+			return c.Status(status)
+		}
+		return c.Render(status, out)
+	})
+
+	typedHandlers.Store(closure(h), &typedInfo{
+		req:     reflect.TypeFor[Req](),
+		res:     reflect.TypeFor[Res](),
+		status:  typedStatus[Res](),
+		handler: funcName(fn),
+	})
+	return h
+}
+
+// typedStatus returns the status of a zero Res, or 200 OK
+func typedStatus[Res any]() int {
+	t := reflect.TypeFor[Res]()
+	v := reflect.New(t)
+	if t.Kind() == reflect.Pointer {
+		v = reflect.New(t.Elem())
+	}
+	if sc, ok := v.Interface().(StatusCoder); ok && sc.StatusCode() != 0 {
+		return sc.StatusCode()
+	}
+	return http.StatusOK
+}
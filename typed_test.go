@@ -0,0 +1,248 @@
+package mux
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type getItem struct {
+	ID      int      `path:"id" validate:"gt=0"`
+	Fields  []string `query:"fields"`
+	Verbose *bool    `query:"verbose"`
+	Tenant  UUID     `header:"X-Tenant"`
+}
+
+type item struct {
+	ID     int      `json:"id" xml:"id"`
+	Fields []string `json:"fields" xml:"field"`
+	Tenant string   `json:"tenant" xml:"tenant"`
+}
+
+type newItem struct {
+	Name  string `json:"name" validate:"required,max=8"`
+	Count int    `json:"count" validate:"gte=1"`
+}
+
+type created struct {
+	ID int `json:"id"`
+}
+
+func (created) StatusCode() int { return 201 }
+
+type deleted struct{}
+
+func (deleted) StatusCode() int { return 204 }
+
+func getItemHandler(c *Context, in getItem) (item, error) {
+	if in.ID == 404 {
+		return item{}, &HTTPError{Status: 404, Message: "no such item"}
+	}
+	return item{ID: in.ID, Fields: in.Fields, Tenant: in.Tenant.String()}, nil
+}
+
+func typedRouter() *Router {
+	r := New()
+	r.GET("/items/{id}", Typed(getItemHandler))
+	r.POST("/items", Typed(func(c *Context, in newItem) (created, error) {
+		return created{ID: len(in.Name)}, nil
+	}))
+	r.DELETE("/items/{id}", Typed(func(c *Context, in getItem) (deleted, error) {
+		return deleted{}, nil
+	}))
+	return r
+}
+
+// -----------------------------------------------------------------------------
+// Typed Handlers
+// -----------------------------------------------------------------------------
+
+func TestTypedBindsParams(t *testing.T) {
+	r := typedRouter()
+
+	req := httptest.NewRequest("GET", "/items/7?fields=a,b", nil)
+	req.Header.Set("X-Tenant", tenant)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	want := `{"id":7,"fields":["a","b"],"tenant":"` + tenant + `"}`
+	if rec.Code != 200 || rec.Body.String() != want {
+		t.Errorf("expected %s, got %d %s", want, rec.Code, rec.Body.String())
+	}
+}
+
+func TestTypedBindsBodyAndStatus(t *testing.T) {
+	r := typedRouter()
+
+	req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"name":"abc","count":2}`))
+	req.Header.Set("Content-Type", MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 201 || rec.Body.String() != `{"id":3}` {
+		t.Errorf("expected 201 from StatusCoder, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("DELETE", "/items/1", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 204 || rec.Body.Len() != 0 {
+		t.Errorf("expected empty 204, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTypedValidation(t *testing.T) {
+	r := typedRouter()
+
+	tests := []struct {
+		method, target, body string
+		want                 []Violation
+	}{
+		{"GET", "/items/0", "", []Violation{{In: "path", Name: "id", Detail: "must be greater than 0"}}},
+		{"GET", "/items/x?verbose=maybe", "", []Violation{
+			{In: "path", Name: "id", Detail: `expected integer, got "x"`},
+			{In: "query", Name: "verbose", Detail: `expected boolean, got "maybe"`},
+		}},
+		{"POST", "/items", `{"name":"much too long","count":0}`, []Violation{
+			{In: "body", Pointer: "/name", Detail: "must be at most 8 characters"},
+			{In: "body", Pointer: "/count", Detail: "must be at least 1"},
+		}},
+		{"POST", "/items", `{"count":1}`, []Violation{{In: "body", Pointer: "/name", Detail: "is required"}}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		var p Problem
+		_ = json.Unmarshal(rec.Body.Bytes(), &p)
+		if rec.Code != 400 || rec.Header().Get("Content-Type") != MIMEApplicationProblemJSON {
+			t.Errorf("%s %s: expected problem, got %d %s", tt.method, tt.target, rec.Code, rec.Body.String())
+			continue
+		}
+		if len(p.Errors) != len(tt.want) {
+			t.Errorf("%s %s: expected %+v, got %+v", tt.method, tt.target, tt.want, p.Errors)
+			continue
+		}
+		for i := range tt.want {
+			if p.Errors[i] != tt.want[i] {
+				t.Errorf("%s %s: expected %+v, got %+v", tt.method, tt.target, tt.want[i], p.Errors[i])
+			}
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	r := typedRouter()
+
+	req := httptest.NewRequest("GET", "/items/404", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 404 {
+		t.Errorf("expected handler error, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/items", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 400 || !strings.Contains(rec.Body.String(), "badly-formed") {
+		t.Errorf("expected 400 bind error, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTypedNegotiation(t *testing.T) {
+	r := typedRouter()
+
+	tests := []struct {
+		accept, contentType string
+		code                int
+	}{
+		{"application/xml", MIMEApplicationXML, 200},
+		{"text/html, application/json;q=0.5", MIMEApplicationJSON, 200},
+		{"text/html", "", 406},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/items/1", nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.accept, tt.code, rec.Code)
+		}
+		if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: expected %s, got %s", tt.accept, tt.contentType, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestTypedSameShapeHandlers(t *testing.T) {
+	// handlers of pointer types share their code, not their types
+	r := New()
+	r.GET("/a", Typed(func(c *Context, in *getItem) (*item, error) { return nil, nil }))
+	r.GET("/b", Typed(func(c *Context, in *newItem) (*created, error) { return nil, nil }))
+	r.GET("/c", func(c *Context) error { return nil })
+
+	routes := r.Routes()
+	if routes[0].Request != "*mux.getItem" || routes[1].Request != "*mux.newItem" {
+		t.Errorf("expected the types of each handler, got %+v %+v", routes[0], routes[1])
+	}
+	if routes[2].Request != "" {
+		t.Errorf("expected plain handler without types, got %+v", routes[2])
+	}
+}
+
+func TestTypedIntrospection(t *testing.T) {
+	r := typedRouter()
+
+	routes := r.Routes()
+	if routes[0].Handler != "mux.getItemHandler" {
+		t.Errorf("expected wrapped function name, got %s", routes[0].Handler)
+	}
+	if routes[0].Request != "mux.getItem" || routes[0].Response != "mux.item" {
+		t.Errorf("expected typed request and response, got %+v", routes[0])
+	}
+
+	doc := r.OpenAPI()
+	post := doc.Paths["/items"].Post
+	if post.Responses["201"] == nil || post.RequestBody == nil {
+		t.Errorf("expected inferred body and 201 response, got %+v", post)
+	}
+	get := doc.Paths["/items/{id}"].Get
+	if len(get.Parameters) != 4 || get.Parameters[0].Schema.Type[0] != "integer" {
+		t.Errorf("expected typed parameters, got %+v", get.Parameters)
+	}
+	if del := doc.Paths["/items/{id}"].Delete; del.Responses["204"] == nil {
+		t.Errorf("expected 204 response, got %+v", del.Responses)
+	}
+
+	r.PUT("/items/{id}", Typed(getItemHandler)).Response(202, nil)
+	put := r.OpenAPI().Paths["/items/{id}"].Put
+	if len(put.Responses) != 1 || put.Responses["202"] == nil {
+		t.Errorf("expected declared response to replace the inferred one, got %+v", put.Responses)
+	}
+}
+
+func TestTypedCustomErrorHandler(t *testing.T) {
+	r := New()
+	r.OnErr(func(c *Context, err error) {
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			_ = c.String(422, ve.Violations[0].Detail)
+		}
+	})
+	r.GET("/items/{id}", Typed(getItemHandler))
+
+	req := httptest.NewRequest("GET", "/items/-1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 422 || rec.Body.String() != "must be greater than 0" {
+		t.Errorf("expected custom handler, got %d %q", rec.Code, rec.Body.String())
+	}
+}
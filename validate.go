@@ -0,0 +1,296 @@
+package mux
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError lists the violations of a request. The default error
+// handler responds with a 400 Problem listing them.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.In + " " + v.Name + v.Pointer + " " + v.Detail
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the validate tags of the struct v and its nested
+// structs. It returns a *ValidationError listing the violations, or nil.
+//
+// The rules are required, omitempty, min, max, len, gt, gte, lt, lte,
+// oneof, email, url, uri, uuid, ipv4, ipv6, datetime, alpha, alphanum,
+// numeric and unique. Rules after dive apply to the elements of slices
+// and maps. Lengths of strings count runes.
+func Validate(v any) error {
+	var errs []Violation
+	validateStruct(reflect.ValueOf(v), "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Violations: errs}
+	}
+	return nil
+}
+
+// validateStruct checks the fields of the struct v. Fields bound from
+// the path, query or headers are reported by parameter name, the others
+// by JSON pointer below ptr.
+func validateStruct(v reflect.Value, ptr string, errs *[]Violation) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f, fv := t.Field(i), v.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if f.Anonymous && name == "" {
+			validateStruct(fv, ptr, errs)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		loc := location{in: "body"}
+		for _, in := range []string{"path", "query", "header"} {
+			if param, _, _ := strings.Cut(f.Tag.Get(in), ","); param != "" {
+				loc = location{in: in, name: param}
+			}
+		}
+		if loc.in == "body" {
+			name, ok := jsonName(f)
+			if !ok {
+				// never decoded from the body
+				continue
+			}
+			loc.ptr = ptr + "/" + escapePointer(name)
+		}
+
+		if tag := f.Tag.Get("validate"); tag != "" && tag != "-" {
+			checkRules(fv, strings.Split(tag, ","), loc, errs)
+		}
+		if loc.in == "body" {
+			validateNested(fv, loc.ptr, errs)
+		}
+	}
+}
+
+// validateNested validates structs within the field value v
+func validateNested(v reflect.Value, ptr string, errs *[]Violation) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != timeType {
+			validateStruct(v, ptr, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			validateNested(v.Index(i), ptr+"/"+strconv.Itoa(i), errs)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			validateNested(v.MapIndex(key), ptr+"/"+escapePointer(fmt.Sprint(key.Interface())), errs)
+		}
+	}
+}
+
+// location is where a validated value was bound from
+type location struct {
+	in, name, ptr string
+}
+
+func (l location) fail(errs *[]Violation, detail string) {
+	*errs = append(*errs, Violation{In: l.in, Name: l.name, Pointer: l.ptr, Detail: detail})
+}
+
+var (
+	alphaPattern    = regexp.MustCompile("^[a-zA-Z]+$")
+	alphanumPattern = regexp.MustCompile("^[a-zA-Z0-9]+$")
+	numericPattern  = regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)
+)
+
+// checkRules checks v against validate rules, stopping at the first
+// violation
+func checkRules(v reflect.Value, rules []string, loc location, errs *[]Violation) {
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "omitempty":
+			if v.IsZero() {
+				return
+			}
+			continue
+		case "required":
+			if v.IsZero() {
+				loc.fail(errs, "is required")
+				return
+			}
+			continue
+		}
+
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+
+		detail := ""
+		switch name {
+		case "dive":
+			checkElems(v, rules[i+1:], loc, errs)
+			return
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			detail = checkBound(v, name, param)
+		case "oneof":
+			if s := fmt.Sprint(v.Interface()); !strings.Contains(" "+param+" ", " "+s+" ") {
+				detail = fmt.Sprintf("must be one of %v", strings.Fields(param))
+			}
+		case "email", "uuid", "uuid4", "uuid_rfc4122", "uuid4_rfc4122", "ipv4", "ipv6", "url", "uri", "http_url":
+			format := name
+			switch name {
+			case "url", "uri", "http_url":
+				format = "uri"
+			case "uuid4", "uuid_rfc4122", "uuid4_rfc4122":
+				format = "uuid"
+			}
+			if v.Kind() == reflect.String && !validFormat(format, v.String()) {
+				detail = "must be a valid " + name
+			}
+		case "datetime":
+			layout := param
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			if _, err := time.Parse(layout, v.String()); v.Kind() == reflect.String && err != nil {
+				detail = "must be a date and time formatted as " + layout
+			}
+		case "alpha":
+			if v.Kind() == reflect.String && !alphaPattern.MatchString(v.String()) {
+				detail = "must contain only letters"
+			}
+		case "alphanum":
+			if v.Kind() == reflect.String && !alphanumPattern.MatchString(v.String()) {
+				detail = "must contain only letters and digits"
+			}
+		case "numeric":
+			if v.Kind() == reflect.String && !numericPattern.MatchString(v.String()) {
+				detail = "must be numeric"
+			}
+		case "unique":
+			if hasDuplicates(v) {
+				detail = "items must be unique"
+			}
+		}
+
+		if detail != "" {
+			loc.fail(errs, detail)
+			return
+		}
+	}
+}
+
+// checkElems checks the elements of a slice, array or map
+func checkElems(v reflect.Value, rules []string, loc location, errs *[]Violation) {
+	elem := func(ev reflect.Value, key string) {
+		l := loc
+		if l.in == "body" {
+			l.ptr += "/" + escapePointer(key)
+		}
+		checkRules(ev, rules, l, errs)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			elem(v.Index(i), strconv.Itoa(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem(v.MapIndex(key), fmt.Sprint(key.Interface()))
+		}
+	}
+}
+
+// checkBound checks a min, max, len, gt, gte, lt or lte rule against
+// the length of strings and collections or the value of numbers
+func checkBound(v reflect.Value, rule, param string) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return ""
+	}
+
+	var n float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return ""
+	}
+
+	var fails bool
+	var word string
+	switch rule {
+	case "min", "gte":
+		fails, word = n < limit, "at least"
+	case "max", "lte":
+		fails, word = n > limit, "at most"
+	case "len":
+		fails, word = unit != "" && n != limit, "exactly"
+	case "gt":
+		fails, word = n <= limit, "more than"
+		if unit == "" {
+			word = "greater than"
+		}
+	case "lt":
+		fails, word = n >= limit, "fewer than"
+		if unit == "" {
+			word = "less than"
+		}
+	}
+	if !fails {
+		return ""
+	}
+
+	verb := "must be "
+	if unit == " items" {
+		verb = "must have "
+	}
+	return verb + word + " " + formatFloat(limit) + unit
+}
+
+// hasDuplicates reports whether a slice or array has equal elements
+func hasDuplicates(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	for i := range v.Len() {
+		for j := range i {
+			if reflect.DeepEqual(v.Index(i).Interface(), v.Index(j).Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package mux
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=5,numeric"`
+}
+
+type signup struct {
+	Email    string            `json:"email" validate:"required,email"`
+	Website  string            `json:"website" validate:"omitempty,url"`
+	Plan     string            `json:"plan" validate:"oneof=free pro"`
+	Score    float64           `json:"score" validate:"gte=0,lte=1"`
+	Tags     []string          `json:"tags" validate:"max=2,unique,dive,alpha"`
+	Labels   map[string]int    `json:"labels" validate:"dive,lt=10"`
+	Home     *address          `json:"home"`
+	Previous []address         `json:"previous"`
+	Nickname *string           `json:"nickname" validate:"min=3"`
+	Extra    map[string]string `json:"-"`
+}
+
+// -----------------------------------------------------------------------------
+// Validate
+// -----------------------------------------------------------------------------
+
+func TestValidateValid(t *testing.T) {
+	nick := "bob"
+	s := signup{
+		Email:    "a@example.com",
+		Plan:     "pro",
+		Score:    0.5,
+		Tags:     []string{"go"},
+		Labels:   map[string]int{"x": 1},
+		Home:     &address{City: "Oslo", Zip: "01234"},
+		Previous: []address{{City: "Bergen"}},
+		Nickname: &nick,
+	}
+	if err := Validate(&s); err != nil {
+		t.Errorf("expected valid struct, got %v", err)
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	nick := "x"
+	s := signup{
+		Email:    "nope",
+		Website:  "example",
+		Plan:     "gold",
+		Score:    1.5,
+		Tags:     []string{"a", "a", "b2"},
+		Labels:   map[string]int{"x": 10},
+		Home:     &address{Zip: "12"},
+		Previous: []address{{City: "Bergen"}, {}},
+		Nickname: &nick,
+	}
+
+	want := []Violation{
+		{In: "body", Pointer: "/email", Detail: "must be a valid email"},
+		{In: "body", Pointer: "/website", Detail: "must be a valid url"},
+		{In: "body", Pointer: "/plan", Detail: "must be one of [free pro]"},
+		{In: "body", Pointer: "/score", Detail: "must be at most 1"},
+		{In: "body", Pointer: "/tags", Detail: "must have at most 2 items"},
+		{In: "body", Pointer: "/labels/x", Detail: "must be less than 10"},
+		{In: "body", Pointer: "/home/city", Detail: "is required"},
+		{In: "body", Pointer: "/home/zip", Detail: "must be exactly 5 characters"},
+		{In: "body", Pointer: "/previous/1/city", Detail: "is required"},
+		{In: "body", Pointer: "/nickname", Detail: "must be at least 3 characters"},
+	}
+
+	err := Validate(s)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(ve.Violations) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), ve.Violations)
+	}
+	for i := range want {
+		if ve.Violations[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], ve.Violations[i])
+		}
+	}
+}
+
+func TestValidateDatetime(t *testing.T) {
+	type event struct {
+		At  string `json:"at" validate:"datetime"`
+		Day string `json:"day" validate:"datetime=2006-01-02"`
+	}
+
+	if err := Validate(event{At: "2026-10-18T12:00:00Z", Day: "2026-10-18"}); err != nil {
+		t.Errorf("expected valid dates, got %v", err)
+	}
+
+	var ve *ValidationError
+	err := Validate(event{At: "2026-10-18", Day: "18.10.2026"})
+	if !errors.As(err, &ve) || len(ve.Violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", err)
+	}
+	if d := ve.Violations[0].Detail; d != "must be a date and time formatted as "+time.RFC3339 {
+		t.Errorf("expected RFC 3339 by default, got %q", d)
+	}
+}
+
+func TestValidateDive(t *testing.T) {
+	err := Validate(signup{Email: "a@example.com", Plan: "free", Tags: []string{"ok", "n0"}})
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Violations) != 1 || ve.Violations[0].Pointer != "/tags/1" {
+		t.Errorf("expected element violation, got %v", err)
+	}
+	if ve.Error() != "invalid request: body /tags/1 must contain only letters" {
+		t.Errorf("unexpected message %q", ve.Error())
+	}
+}
+
+func TestValidateJSONNames(t *testing.T) {
+	type form struct {
+		Dash    string `json:"-," validate:"required"`
+		Skipped string `json:"-" validate:"required"`
+		Plain   string `validate:"required"`
+		ID      string `json:"-" path:"id" validate:"required"`
+	}
+
+	var ve *ValidationError
+	if err := Validate(form{}); !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	var got []string
+	for _, v := range ve.Violations {
+		got = append(got, v.In+" "+v.Name+v.Pointer)
+	}
+	if want := []string{"body /-", "body /Plain", "path id"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// the schema names the body fields the same way
+	s := newSchemaBuilder().object(reflect.TypeFor[form]())
+	names := slices.Sorted(maps.Keys(s.Properties))
+	if want := []string{"-", "Plain"}; !slices.Equal(names, want) {
+		t.Errorf("expected properties %v, got %v", want, names)
+	}
+}
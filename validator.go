@@ -0,0 +1,822 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidatorConfig configures OpenAPIValidator
+type ValidatorConfig struct {
+	// BasePath is stripped from request paths before they are matched
+	// with the document paths. Default: the path of the first server URL
+	BasePath string
+
+	// MaxBodySize limits the request bodies the validator reads. Default:
+	// 1 MiB
+	MaxBodySize int64
+
+	// Responses validates the status and JSON body of responses too.
+	// Responses are buffered until validated, so this is meant for tests.
+	Responses bool
+
+	// OnResponseError is called with the violations of a response, which
+	// is then written unchanged. Default: replace the response with a 500
+	// problem listing the violations
+	OnResponseError func(c *Context, violations []Violation)
+}
+
+// ParseOpenAPI parses an OpenAPI 3 JSON document
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	var doc OpenAPI
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("mux: invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("mux: unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// OpenAPIValidator returns a middleware that validates the path
+// parameters, query, headers, cookies and JSON body of requests against
+// the matching operation of doc before the handler runs. Invalid requests
+// get a 400 Problem listing the violations. Requests matching no
+// operation are passed through.
+//
+// Query arrays are read from repeated parameters or a comma-separated
+// value. It panics if doc has unresolved references or patterns Go
+// cannot compile.
+func OpenAPIValidator(doc *OpenAPI, config ...ValidatorConfig) Middleware {
+	cfg := ValidatorConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.BasePath == "" && len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			cfg.BasePath = u.Path
+		}
+	}
+	cfg.BasePath = strings.TrimSuffix(cfg.BasePath, "/")
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1_048_576
+	}
+
+	v := newValidator(doc)
+	v.maxBody = cfg.MaxBodySize
+
+	return func(next Handler) Handler {
+		return func(c *Context) error {
+			op, values := v.match(c.r, cfg.BasePath)
+			if op == nil {
+				return next(c)
+			}
+			if errs := v.request(c, op, values); len(errs) > 0 {
+				return c.Problem(&Problem{
+					Status: http.StatusBadRequest,
+					Detail: "request does not match the API schema",
+					Errors: errs,
+				})
+			}
+			if !cfg.Responses {
+				return next(c)
+			}
+
+			rw := c.w
+			buf := &bufferedWriter{ResponseWriter: rw}
+			c.w = &ResponseWriter{ResponseWriter: buf}
+			err := next(c)
+			c.w = rw
+
+			if err == nil {
+				if errs := v.response(op, buf); len(errs) > 0 {
+					if cfg.OnResponseError == nil {
+						return c.Problem(&Problem{
+							Status: http.StatusInternalServerError,
+							Detail: "response does not match the API schema",
+							Errors: errs,
+						})
+					}
+					cfg.OnResponseError(c, errs)
+				}
+			}
+			if buf.status != 0 {
+				rw.WriteHeader(buf.status)
+			}
+			if buf.body.Len() > 0 {
+				if _, werr := rw.Write(buf.body.Bytes()); err == nil {
+					err = werr
+				}
+			}
+			return err
+		}
+	}
+}
+
+// bufferedWriter holds a response until it is validated
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// validator validates requests and responses against a document
+type validator struct {
+	schemas  map[string]*Schema
+	patterns map[string]*regexp.Regexp
+	paths    []docPath
+	maxBody  int64
+}
+
+// docPath is a path template of the document
+type docPath struct {
+	re    *regexp.Regexp
+	names []string
+	ops   map[string]*docOperation
+}
+
+// docOperation is an operation with its references resolved
+type docOperation struct {
+	params    []*Parameter
+	body      *RequestBody
+	responses map[string]*Response
+}
+
+var docMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+func newValidator(doc *OpenAPI) *validator {
+	comps := doc.Components
+	if comps == nil {
+		comps = &Components{}
+	}
+	v := &validator{schemas: comps.Schemas, patterns: make(map[string]*regexp.Regexp)}
+
+	seen := make(map[*Schema]bool)
+	for _, s := range comps.Schemas {
+		v.compile(s, seen)
+	}
+
+	for path, item := range doc.Paths {
+		p := docPath{ops: make(map[string]*docOperation)}
+		p.re, p.names = compileTemplate(path)
+
+		for _, method := range docMethods {
+			op := item.Operation(method)
+			if op == nil {
+				continue
+			}
+
+			dop := &docOperation{responses: make(map[string]*Response)}
+			for _, param := range slices.Concat(item.Parameters, op.Parameters) {
+				param = resolveRef(param, param.Ref, "parameters", comps.Parameters)
+				dop.params = slices.DeleteFunc(dop.params, func(p *Parameter) bool {
+					return p.In == param.In && p.Name == param.Name
+				})
+				dop.params = append(dop.params, param)
+				v.compile(param.Schema, seen)
+			}
+			if op.RequestBody != nil {
+				dop.body = resolveRef(op.RequestBody, op.RequestBody.Ref, "requestBodies", comps.RequestBodies)
+				for _, mt := range dop.body.Content {
+					v.compile(mt.Schema, seen)
+				}
+			}
+			for status, res := range op.Responses {
+				res = resolveRef(res, res.Ref, "responses", comps.Responses)
+				dop.responses[status] = res
+				for _, mt := range res.Content {
+					v.compile(mt.Schema, seen)
+				}
+			}
+			p.ops[method] = dop
+		}
+		v.paths = append(v.paths, p)
+	}
+
+	// concrete paths before templated ones
+	slices.SortFunc(v.paths, func(a, b docPath) int {
+		if n := len(a.names) - len(b.names); n != 0 {
+			return n
+		}
+		return len(b.re.String()) - len(a.re.String())
+	})
+	return v
+}
+
+// resolveRef returns the component a reference like
+// #/components/parameters/id names, or v if ref is empty
+func resolveRef[T any](v *T, ref, kind string, components map[string]*T) *T {
+	if ref == "" {
+		return v
+	}
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok || components[name] == nil {
+		panic(fmt.Sprintf("mux: unresolved OpenAPI reference %q", ref))
+	}
+	return components[name]
+}
+
+// compile checks the references of s and compiles its patterns
+func (v *validator) compile(s *Schema, seen map[*Schema]bool) {
+	if s == nil || seen[s] {
+		return
+	}
+	seen[s] = true
+
+	if s.Ref != "" {
+		v.compile(v.resolve(s), seen)
+	}
+	if s.Pattern != "" && v.patterns[s.Pattern] == nil {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			panic(fmt.Sprintf("mux: invalid schema pattern %q: %v", s.Pattern, err))
+		}
+		v.patterns[s.Pattern] = re
+	}
+
+	for _, p := range s.Properties {
+		v.compile(p, seen)
+	}
+	for _, sub := range slices.Concat(s.AllOf, s.AnyOf, s.OneOf) {
+		v.compile(sub, seen)
+	}
+	v.compile(s.Items, seen)
+	v.compile(s.AdditionalProperties, seen)
+	v.compile(s.Not, seen)
+}
+
+// resolve follows the reference of s to a component schema
+func (v *validator) resolve(s *Schema) *Schema {
+	for range 32 {
+		if s == nil || s.Ref == "" {
+			return s
+		}
+		s = resolveRef(s, s.Ref, "schemas", v.schemas)
+	}
+	panic(fmt.Sprintf("mux: cyclic OpenAPI reference %q", s.Ref))
+}
+
+// compileTemplate compiles a path template like /users/{id} to a
+// regular expression and returns the parameter names
+func compileTemplate(path string) (*regexp.Regexp, []string) {
+	var sb strings.Builder
+	var names []string
+
+	sb.WriteByte('^')
+	for {
+		i := strings.IndexByte(path, '{')
+		if i < 0 {
+			break
+		}
+		end := strings.IndexByte(path[i:], '}')
+		if end < 0 {
+			break
+		}
+		end += i
+
+		sb.WriteString(regexp.QuoteMeta(path[:i]))
+		sb.WriteString("([^/]+)")
+		names = append(names, path[i+1:end])
+		path = path[end+1:]
+	}
+	sb.WriteString(regexp.QuoteMeta(path))
+	sb.WriteByte('$')
+
+	return regexp.MustCompile(sb.String()), names
+}
+
+// match returns the operation of the request and its path parameters
+func (v *validator) match(req *http.Request, base string) (*docOperation, map[string]string) {
+	path := req.URL.Path
+	if base != "" {
+		if !matchPrefix(base, path, false) {
+			return nil, nil
+		}
+		path = path[len(base):]
+		if path == "" {
+			path = "/"
+		}
+	}
+
+	for _, p := range v.paths {
+		m := p.re.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+		op := p.ops[req.Method]
+		if op == nil && req.Method == http.MethodHead {
+			op = p.ops[http.MethodGet]
+		}
+		if op == nil {
+			// another template may match the path with the method
+			continue
+		}
+
+		values := make(map[string]string, len(p.names))
+		for i, name := range p.names {
+			values[name] = m[i+1]
+		}
+		return op, values
+	}
+	return nil, nil
+}
+
+// request validates the parameters and body of a request. The body is
+// restored for the handler.
+func (v *validator) request(c *Context, op *docOperation, values map[string]string) []Violation {
+	var errs []Violation
+
+	for _, p := range op.params {
+		var raw []string
+		var ok bool
+		switch p.In {
+		case "path":
+			var s string
+			s, ok = values[p.Name]
+			raw = []string{s}
+		case "query":
+			raw, ok = c.queries()[p.Name]
+		case "header":
+			raw, ok = c.r.Header[http.CanonicalHeaderKey(p.Name)]
+		case "cookie":
+			if ck, err := c.r.Cookie(p.Name); err == nil {
+				raw, ok = []string{ck.Value}, true
+			}
+		}
+
+		if !ok || len(raw) == 0 {
+			if p.Required || p.In == "path" {
+				errs = append(errs, Violation{In: p.In, Name: p.Name, Detail: "is required"})
+			}
+			continue
+		}
+		if p.Schema == nil {
+			continue
+		}
+
+		sc := &schemaCheck{v: v, in: p.In, name: p.Name}
+		sc.validate(p.Schema, v.coerce(p.Schema, raw), "")
+		errs = append(errs, sc.errs...)
+	}
+
+	if op.body != nil {
+		errs = append(errs, v.requestBody(c, op.body)...)
+	}
+	return errs
+}
+
+// requestBody validates a JSON request body
+func (v *validator) requestBody(c *Context, body *RequestBody) []Violation {
+	data, err := io.ReadAll(http.MaxBytesReader(c.w, c.r.Body, v.maxBody))
+	c.r.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return []Violation{{In: "body", Detail: err.Error()}}
+	}
+
+	if len(data) == 0 {
+		if body.Required {
+			return []Violation{{In: "body", Detail: "is required"}}
+		}
+		return nil
+	}
+
+	ct := c.ContentType()
+	mt := mediaType(body.Content, ct)
+	if mt == nil {
+		return []Violation{{In: "header", Name: "Content-Type", Detail: fmt.Sprintf("unsupported media type %q", ct)}}
+	}
+	if !isJSON(ct) || mt.Schema == nil {
+		return nil
+	}
+
+	val, err := decodeValue(data)
+	if err != nil {
+		return []Violation{{In: "body", Detail: "body contains badly-formed JSON"}}
+	}
+	sc := &schemaCheck{v: v, in: "body"}
+	sc.validate(mt.Schema, val, "")
+	return sc.errs
+}
+
+// response validates the status and JSON body of a buffered response
+func (v *validator) response(op *docOperation, w *bufferedWriter) []Violation {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	res := op.responses[strconv.Itoa(status)]
+	if res == nil {
+		res = op.responses[strconv.Itoa(status/100)+"XX"]
+	}
+	if res == nil {
+		res = op.responses["default"]
+	}
+	if res == nil {
+		return []Violation{{In: "response", Detail: fmt.Sprintf("status %d is not documented", status)}}
+	}
+	if len(res.Content) == 0 || w.body.Len() == 0 {
+		return nil
+	}
+
+	ct, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	mt := mediaType(res.Content, ct)
+	if mt == nil {
+		return []Violation{{In: "response", Name: "Content-Type", Detail: fmt.Sprintf("undocumented media type %q", ct)}}
+	}
+	if !isJSON(ct) || mt.Schema == nil {
+		return nil
+	}
+
+	val, err := decodeValue(w.body.Bytes())
+	if err != nil {
+		return []Violation{{In: "response", Detail: "body contains badly-formed JSON"}}
+	}
+	sc := &schemaCheck{v: v, in: "response"}
+	sc.validate(mt.Schema, val, "")
+	return sc.errs
+}
+
+// mediaType returns the content entry for the media type ct, trying
+// type/* and */* ranges after an exact match
+func mediaType(content map[string]*MediaType, ct string) *MediaType {
+	if mt, ok := content[ct]; ok {
+		return mt
+	}
+	typ, _, _ := strings.Cut(ct, "/")
+	if mt, ok := content[typ+"/*"]; ok {
+		return mt
+	}
+	return content["*/*"]
+}
+
+// isJSON reports whether the media type is JSON
+func isJSON(ct string) bool {
+	return ct == MIMEApplicationJSON || strings.HasSuffix(ct, "+json")
+}
+
+// decodeValue decodes a single JSON value, keeping numbers as json.Number
+func decodeValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("body must only contain a single JSON value")
+	}
+	return val, nil
+}
+
+// coerce converts the raw values of a parameter to the JSON value its
+// schema expects
+func (v *validator) coerce(s *Schema, raw []string) any {
+	s = v.resolve(s)
+	if !s.Type.Has("array") {
+		return v.scalar(s, raw[0])
+	}
+
+	if len(raw) == 1 {
+		raw = strings.Split(raw[0], ",")
+	}
+	items := make([]any, len(raw))
+	for i, r := range raw {
+		items[i] = v.scalar(s.Items, r)
+	}
+	return items
+}
+
+// scalar converts a raw parameter value to a number or boolean when its
+// schema allows one
+func (v *validator) scalar(s *Schema, raw string) any {
+	s = v.resolve(s)
+	if s == nil {
+		return raw
+	}
+	for _, typ := range s.Type {
+		switch typ {
+		case "integer", "number":
+			if isJSONNumber(raw) {
+				return json.Number(raw)
+			}
+		case "boolean":
+			if raw == "true" || raw == "false" {
+				return raw == "true"
+			}
+		}
+	}
+	return raw
+}
+
+// isJSONNumber reports whether s is a JSON number literal
+func isJSONNumber(s string) bool {
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// schemaCheck collects the violations of a value
+type schemaCheck struct {
+	v    *validator
+	in   string
+	name string
+	errs []Violation
+}
+
+func (sc *schemaCheck) fail(ptr, detail string) {
+	sc.errs = append(sc.errs, Violation{In: sc.in, Name: sc.name, Pointer: ptr, Detail: detail})
+}
+
+// matches reports whether val is valid against s
+func (sc *schemaCheck) matches(s *Schema, val any, ptr string) bool {
+	sub := &schemaCheck{v: sc.v, in: sc.in, name: sc.name}
+	sub.validate(s, val, ptr)
+	return len(sub.errs) == 0
+}
+
+// validate checks val against s. ptr is the JSON pointer of val.
+func (sc *schemaCheck) validate(s *Schema, val any, ptr string) {
+	s = sc.v.resolve(s)
+	if s == nil {
+		return
+	}
+
+	if s.Not != nil {
+		if isEmptySchema(s.Not) {
+			sc.fail(ptr, "is not allowed")
+			return
+		}
+		if sc.matches(s.Not, val, ptr) {
+			sc.fail(ptr, "must not match the schema")
+		}
+	}
+	for _, sub := range s.AllOf {
+		sc.validate(sub, val, ptr)
+	}
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool { return sc.matches(sub, val, ptr) }) {
+		sc.fail(ptr, "must match at least one schema")
+	}
+	if len(s.OneOf) > 0 {
+		n := 0
+		for _, sub := range s.OneOf {
+			if sc.matches(sub, val, ptr) {
+				n++
+			}
+		}
+		if n != 1 {
+			sc.fail(ptr, fmt.Sprintf("must match exactly one schema, matches %d", n))
+		}
+	}
+
+	if val == nil && s.Type.Has("null") {
+		return
+	}
+	if len(s.Type) > 0 && !typeMatches(s.Type, val) {
+		sc.fail(ptr, fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), jsonType(val)))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return jsonEqual(e, val) }) {
+		sc.fail(ptr, fmt.Sprintf("must be one of %v", s.Enum))
+	}
+
+	switch val := val.(type) {
+	case string:
+		sc.string(s, val, ptr)
+	case json.Number:
+		sc.number(s, val, ptr)
+	case []any:
+		sc.array(s, val, ptr)
+	case map[string]any:
+		sc.object(s, val, ptr)
+	}
+}
+
+func (sc *schemaCheck) string(s *Schema, val, ptr string) {
+	n := utf8.RuneCountInString(val)
+	if s.MinLength != nil && n < *s.MinLength {
+		sc.fail(ptr, fmt.Sprintf("must be at least %d characters", *s.MinLength))
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		sc.fail(ptr, fmt.Sprintf("must be at most %d characters", *s.MaxLength))
+	}
+	if s.Pattern != "" && !sc.v.patterns[s.Pattern].MatchString(val) {
+		sc.fail(ptr, "must match pattern "+s.Pattern)
+	}
+	if !validFormat(s.Format, val) {
+		sc.fail(ptr, "must be a valid "+s.Format)
+	}
+}
+
+func (sc *schemaCheck) number(s *Schema, val json.Number, ptr string) {
+	n, _ := val.Float64()
+	switch {
+	case s.Minimum != nil && n < *s.Minimum:
+		sc.fail(ptr, "must be at least "+formatFloat(*s.Minimum))
+	case s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum:
+		sc.fail(ptr, "must be greater than "+formatFloat(*s.ExclusiveMinimum))
+	}
+	switch {
+	case s.Maximum != nil && n > *s.Maximum:
+		sc.fail(ptr, "must be at most "+formatFloat(*s.Maximum))
+	case s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum:
+		sc.fail(ptr, "must be less than "+formatFloat(*s.ExclusiveMaximum))
+	}
+	if s.Format == "int32" && (n < math.MinInt32 || n > math.MaxInt32) {
+		sc.fail(ptr, "must be a valid int32")
+	}
+}
+
+func (sc *schemaCheck) array(s *Schema, val []any, ptr string) {
+	if s.MinItems != nil && len(val) < *s.MinItems {
+		sc.fail(ptr, fmt.Sprintf("must have at least %d items", *s.MinItems))
+	}
+	if s.MaxItems != nil && len(val) > *s.MaxItems {
+		sc.fail(ptr, fmt.Sprintf("must have at most %d items", *s.MaxItems))
+	}
+	if s.UniqueItems {
+		for i := range val {
+			if slices.ContainsFunc(val[:i], func(e any) bool { return jsonEqual(e, val[i]) }) {
+				sc.fail(ptr, "items must be unique")
+				break
+			}
+		}
+	}
+	if s.Items != nil {
+		for i, item := range val {
+			sc.validate(s.Items, item, ptr+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (sc *schemaCheck) object(s *Schema, val map[string]any, ptr string) {
+	for _, name := range s.Required {
+		if _, ok := val[name]; !ok {
+			sc.fail(ptr+"/"+escapePointer(name), "is required")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(val)) {
+		if ps, ok := s.Properties[name]; ok {
+			sc.validate(ps, val[name], ptr+"/"+escapePointer(name))
+		} else if s.AdditionalProperties != nil {
+			sc.validate(s.AdditionalProperties, val[name], ptr+"/"+escapePointer(name))
+		}
+	}
+}
+
+// typeMatches reports whether val has one of the JSON types
+func typeMatches(types SchemaType, val any) bool {
+	for _, typ := range types {
+		switch v := val.(type) {
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case json.Number:
+			if typ == "number" {
+				return true
+			}
+			if n, err := v.Float64(); typ == "integer" && err == nil && n == math.Trunc(n) {
+				return true
+			}
+		case []any:
+			if typ == "array" {
+				return true
+			}
+		case map[string]any:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON type name of a decoded value
+func jsonType(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// jsonEqual reports whether two JSON values are equal, comparing numbers
+// by value
+func jsonEqual(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// isEmptySchema reports whether s has no keywords, so it accepts any value
+func isEmptySchema(s *Schema) bool {
+	return reflect.ValueOf(*s).IsZero()
+}
+
+// validFormat reports whether s is valid in the format. Unknown formats
+// accept any value.
+func validFormat(format, s string) bool {
+	var err error
+	switch format {
+	case "email":
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uuid":
+		_, err = ParseUUID(s)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "ipv4":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is6()
+	case "uri":
+		var u *url.URL
+		u, err = url.Parse(s)
+		return err == nil && u.Scheme != ""
+	}
+	return err == nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escapePointer escapes a JSON pointer reference token
+func escapePointer(s string) string {
+	if !strings.ContainsAny(s, "~/") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
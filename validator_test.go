@@ -0,0 +1,303 @@
+package mux
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const petstore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com/api"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}, "maxItems": 2}}
+        ],
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}}
+      },
+      "post": {
+        "parameters": [{"$ref": "#/components/parameters/Tenant"}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewPet"}}}},
+        "responses": {"201": {"description": "Created"}, "4XX": {"description": "Client error"}}
+      }
+    },
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int32"}}],
+      "get": {"responses": {"default": {"description": "Pet"}}}
+    },
+    "/pets/mine": {
+      "get": {"responses": {"200": {"description": "Mine"}}}
+    }
+  },
+  "components": {
+    "parameters": {
+      "Tenant": {"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+    },
+    "schemas": {
+      "NewPet": {
+        "type": "object",
+        "required": ["name"],
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "minLength": 1, "pattern": "^[A-Z]"},
+          "age": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+          "kind": {"type": "string", "enum": ["cat", "dog"]},
+          "owner": {"type": "string", "format": "email", "nullable": true}
+        }
+      },
+      "Pet": {
+        "allOf": [
+          {"$ref": "#/components/schemas/NewPet"},
+          {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}
+        ]
+      }
+    }
+  }
+}`
+
+const tenant = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+func petstoreRouter(t *testing.T, config ...ValidatorConfig) *Router {
+	doc, err := ParseOpenAPI([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+	api := r.Group("/api")
+	api.Use(OpenAPIValidator(doc, config...))
+	api.GET("/pets", func(c *Context) error {
+		return c.OK([]M{{"id": 1, "name": "Rex"}})
+	})
+	api.POST("/pets", func(c *Context) error {
+		var pet M
+		if err := c.Bind(&pet); err != nil {
+			return err
+		}
+		return c.Created(pet)
+	})
+	api.GET("/pets/{id}", func(c *Context) error { return c.String(200, c.Param("id")) })
+	api.GET("/pets/mine", func(c *Context) error { return c.String(200, "mine") })
+	return r
+}
+
+func validate(r *Router, method, target, body string, headers ...string) (*httptest.ResponseRecorder, Problem) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var p Problem
+	if rec.Header().Get("Content-Type") == MIMEApplicationProblemJSON {
+		_ = json.Unmarshal(rec.Body.Bytes(), &p)
+	}
+	return rec, p
+}
+
+// -----------------------------------------------------------------------------
+// OpenAPI Validator
+// -----------------------------------------------------------------------------
+
+func TestValidatorParameters(t *testing.T) {
+	r := petstoreRouter(t)
+
+	tests := []struct {
+		target string
+		code   int
+		errors []Violation
+	}{
+		{"/api/pets", 200, nil},
+		{"/api/pets?limit=10&tags=a,b", 200, nil},
+		{"/api/pets?limit=0", 400, []Violation{{In: "query", Name: "limit", Detail: "must be at least 1"}}},
+		{"/api/pets?limit=ten", 400, []Violation{{In: "query", Name: "limit", Detail: "expected integer, got string"}}},
+		{"/api/pets?tags=a&tags=b&tags=c", 400, []Violation{{In: "query", Name: "tags", Detail: "must have at most 2 items"}}},
+		{"/api/pets/7", 200, nil},
+		{"/api/pets/mine", 200, nil},
+		{"/api/pets/99999999999", 400, []Violation{{In: "path", Name: "id", Detail: "must be a valid int32"}}},
+	}
+	for _, tt := range tests {
+		rec, p := validate(r, "GET", tt.target, "")
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d %s", tt.target, tt.code, rec.Code, rec.Body.String())
+			continue
+		}
+		if len(p.Errors) != len(tt.errors) {
+			t.Errorf("%s: expected %+v, got %+v", tt.target, tt.errors, p.Errors)
+			continue
+		}
+		for i := range tt.errors {
+			if p.Errors[i] != tt.errors[i] {
+				t.Errorf("%s: expected %+v, got %+v", tt.target, tt.errors[i], p.Errors[i])
+			}
+		}
+	}
+}
+
+func TestValidatorBody(t *testing.T) {
+	r := petstoreRouter(t)
+
+	rec, _ := validate(r, "POST", "/api/pets", `{"name":"Rex","age":2,"owner":null}`, "X-Tenant", tenant)
+	if rec.Code != 201 || !strings.Contains(rec.Body.String(), "Rex") {
+		t.Errorf("expected valid body to reach the handler, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec, p := validate(r, "POST", "/api/pets", `{"name":"rex","age":0,"kind":"fish","owner":"nope","color":"red"}`)
+	if rec.Code != 400 || p.Status != 400 || p.Title != "Bad Request" {
+		t.Fatalf("expected problem response, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	want := []Violation{
+		{In: "header", Name: "X-Tenant", Detail: "is required"},
+		{In: "body", Pointer: "/age", Detail: "must be greater than 0"},
+		{In: "body", Pointer: "/color", Detail: "is not allowed"},
+		{In: "body", Pointer: "/kind", Detail: "must be one of [cat dog]"},
+		{In: "body", Pointer: "/name", Detail: "must match pattern ^[A-Z]"},
+		{In: "body", Pointer: "/owner", Detail: "must be a valid email"},
+	}
+	if len(p.Errors) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), p.Errors)
+	}
+	for i := range want {
+		if p.Errors[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], p.Errors[i])
+		}
+	}
+}
+
+func TestValidatorBodyErrors(t *testing.T) {
+	r := petstoreRouter(t)
+
+	tests := []struct {
+		body, detail string
+	}{
+		{"", "is required"},
+		{`{"name":`, "body contains badly-formed JSON"},
+		{`[1]`, "expected object, got array"},
+		{`{}`, "is required"},
+	}
+	for _, tt := range tests {
+		_, p := validate(r, "POST", "/api/pets", tt.body, "X-Tenant", tenant)
+		if len(p.Errors) != 1 || p.Errors[0].Detail != tt.detail {
+			t.Errorf("%q: expected %q, got %+v", tt.body, tt.detail, p.Errors)
+		}
+	}
+
+	_, p := validate(r, "POST", "/api/pets", `{"name":"Rex"}`, "X-Tenant", tenant, "Content-Type", "text/plain")
+	if len(p.Errors) != 1 || p.Errors[0].Name != "Content-Type" {
+		t.Errorf("expected unsupported media type, got %+v", p.Errors)
+	}
+}
+
+func TestValidatorBodySize(t *testing.T) {
+	r := petstoreRouter(t, ValidatorConfig{MaxBodySize: 16})
+
+	_, p := validate(r, "POST", "/api/pets", `{"name":"Rexxxxxxxxxxxxx"}`, "X-Tenant", tenant)
+	if len(p.Errors) != 1 || !strings.Contains(p.Errors[0].Detail, "too large") {
+		t.Errorf("expected body size violation, got %+v", p.Errors)
+	}
+}
+
+func TestValidatorMethodOnLaterTemplate(t *testing.T) {
+	doc, _ := ParseOpenAPI([]byte(petstore))
+	doc.Paths["/pets/{id}"].Delete = &Operation{Responses: map[string]*Response{"204": {Description: "Deleted"}}}
+
+	r := New()
+	r.Use(OpenAPIValidator(doc, ValidatorConfig{BasePath: "/"}))
+	r.DELETE("/pets/{id}", func(c *Context) error { return c.NoContent() })
+
+	rec, p := validate(r, "DELETE", "/pets/mine", "")
+	if rec.Code != 400 || len(p.Errors) != 1 || p.Errors[0].Name != "id" {
+		t.Errorf("expected the template with the method to validate, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestValidatorResponses(t *testing.T) {
+	doc, _ := ParseOpenAPI([]byte(petstore))
+
+	r := New()
+	r.Use(OpenAPIValidator(doc, ValidatorConfig{BasePath: "/", Responses: true}))
+	r.GET("/pets", func(c *Context) error { return c.OK([]M{{"name": "Rex"}}) })
+	r.GET("/pets/mine", func(c *Context) error { return c.String(418, "teapot") })
+	r.GET("/pets/{id}", func(c *Context) error { return c.String(200, "ok") })
+
+	rec, p := validate(r, "GET", "/pets", "")
+	if rec.Code != 500 || len(p.Errors) != 1 || p.Errors[0].Pointer != "/0/id" || p.Errors[0].In != "response" {
+		t.Errorf("expected response violation, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec, p = validate(r, "GET", "/pets/mine", "")
+	if rec.Code != 500 || p.Errors[0].Detail != "status 418 is not documented" {
+		t.Errorf("expected undocumented status, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = validate(r, "GET", "/pets/3", "")
+	if rec.Code != 200 || rec.Body.String() != "ok" {
+		t.Errorf("expected buffered response to be written, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	var reported []Violation
+	r = New()
+	r.Use(OpenAPIValidator(doc, ValidatorConfig{BasePath: "/", Responses: true, OnResponseError: func(c *Context, v []Violation) {
+		reported = v
+	}}))
+	r.GET("/pets/mine", func(c *Context) error { return c.String(418, "teapot") })
+
+	rec, _ = validate(r, "GET", "/pets/mine", "")
+	if rec.Code != 418 || len(reported) != 1 {
+		t.Errorf("expected reported violation and unchanged response, got %d %+v", rec.Code, reported)
+	}
+}
+
+func TestValidatorGeneratedDocument(t *testing.T) {
+	spec := New()
+	spec.POST("/orgs/{org}/teams", listUsers).Request(createUser{})
+
+	r := New()
+	r.Use(OpenAPIValidator(spec.OpenAPI()))
+	r.POST("/orgs/{org}/teams", func(c *Context) error { return c.Status(201) })
+
+	rec, p := validate(r, "POST", "/orgs/acme/teams?dry_run=yes", `{"name":"A","email":"a@example.com","tags":["ok","no!"]}`)
+	if rec.Code != 400 {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	want := []Violation{
+		{In: "query", Name: "dry_run", Detail: "expected boolean, got string"},
+		{In: "header", Name: "X-Trace", Detail: "is required"},
+		{In: "body", Pointer: "/name", Detail: "must be at least 2 characters"},
+		{In: "body", Pointer: "/tags/1", Detail: "must match pattern ^[a-zA-Z0-9]+$"},
+	}
+	if len(p.Errors) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), p.Errors)
+	}
+	for i := range want {
+		if p.Errors[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], p.Errors[i])
+		}
+	}
+}
+
+func TestValidatorUnresolvedReferencePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unresolved reference")
+		}
+	}()
+	doc, _ := ParseOpenAPI([]byte(`{"openapi":"3.1.0","paths":{"/x":{"get":{"parameters":[{"$ref":"#/components/parameters/Nope"}],"responses":{}}}}}`))
+	OpenAPIValidator(doc)
+}
+
+func TestParseOpenAPIVersion(t *testing.T) {
+	if _, err := ParseOpenAPI([]byte(`{"swagger":"2.0"}`)); err == nil {
+		t.Error("expected error for Swagger 2 document")
+	}
+}
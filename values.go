@@ -1,11 +1,12 @@
 package mux
 
 const (
-	MIMETextXML         = "text/xml"
-	MIMETextHTML        = "text/html"
-	MIMETextPlain       = "text/plain"
-	MIMEApplicationXML  = "application/xml"
-	MIMEApplicationJSON = "application/json"
-	MIMEApplicationForm = "application/x-www-form-urlencoded"
-	MIMEMultipartForm   = "multipart/form-data"
+	MIMETextXML                = "text/xml"
+	MIMETextHTML               = "text/html"
+	MIMETextPlain              = "text/plain"
+	MIMEApplicationXML         = "application/xml"
+	MIMEApplicationJSON        = "application/json"
+	MIMEApplicationProblemJSON = "application/problem+json"
+	MIMEApplicationForm        = "application/x-www-form-urlencoded"
+	MIMEMultipartForm          = "multipart/form-data"
 )
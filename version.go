@@ -0,0 +1,187 @@
+package mux
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// VersionStrategy is where the API version of a request is read.
+// Strategies can be combined and are tried in the order below.
+type VersionStrategy int
+
+const (
+	// VersionPath serves version groups under a /v{version} prefix
+	VersionPath VersionStrategy = 1 << iota
+
+	// VersionHeader reads the version from a request header
+	VersionHeader
+
+	// VersionAccept reads the version from the Accept media type, either
+	// a version parameter or a vendor type like application/vnd.acme.v2+json
+	VersionAccept
+)
+
+// VersionConfig configures API versioning
+type VersionConfig struct {
+	// Strategy to select versions. Default: VersionPath
+	Strategy VersionStrategy
+
+	// Header read by VersionHeader. Default: API-Version
+	Header string
+
+	// Default version of requests that name none. Without a default,
+	// such requests to versioned routes get 400.
+	Default string
+}
+
+// Version registers the routes fn adds to g for the API version. The
+// same pattern can be registered in several versions, and requests are
+// routed by the configured VersionStrategy. Unknown versions get 400,
+// or 406 when requested through Accept.
+func (r *Router) Version(version string, fn func(g *Group)) {
+	(&Group{router: r}).Version(version, fn)
+}
+
+// Version registers the routes fn adds to g for the API version under
+// the group prefix
+func (g *Group) Version(version string, fn func(g *Group)) {
+	vg := g.Group("")
+	vg.version = version
+	vg.vprefix = g.prefix + "/v" + version
+
+	t := g.router.table
+	if !slices.Contains(t.versions, version) {
+		t.versions = append(t.versions, version)
+	}
+	if !slices.Contains(t.vprefixes, g.prefix) {
+		t.vprefixes = append(t.vprefixes, g.prefix)
+	}
+
+	fn(vg)
+}
+
+// Version returns the API version of the matched route, or "" for
+// unversioned routes
+func (c *Context) Version() string {
+	c.checkReleased()
+	if c.group == nil {
+		return ""
+	}
+	return c.group.version
+}
+
+// handleVersion registers a handler of the version group g: under the
+// version prefix for VersionPath, and with the other routes of the
+// pattern for the other strategies and the default version
+func (r *Router) handleVersion(t *table, mux Matcher, g *Group, rt *Route, cons constraints, hf http.Handler) {
+	method := rt.method
+	vc := r.cfg.Versioning
+
+	if vc.Strategy&VersionPath != 0 {
+		path, _ := r.routePath(mux, rt.vpattern)
+		r.handlePattern(t, mux, g, rt.vpattern, routeKey(method, path), "", cons, hf)
+	}
+	if vc.Strategy&^VersionPath == 0 && vc.Default == "" {
+		return
+	}
+	r.handlePattern(t, mux, g, rt.pattern, rt.key, g.version, cons, hf)
+}
+
+// selectVersion returns the version of a request to a versioned pattern,
+// or the status of a version error, and adds the Vary headers
+func (r *Router) selectVersion(t *table, w http.ResponseWriter, req *http.Request) (string, int) {
+	vc := r.cfg.Versioning
+	if vc.Strategy&VersionHeader != 0 {
+		clientHeader(w).Add("Vary", vc.Header)
+	}
+	if vc.Strategy&VersionAccept != 0 {
+		clientHeader(w).Add("Vary", "Accept")
+	}
+	return r.requestVersion(t, req)
+}
+
+// requestVersion returns the version a request names, or the default.
+// The status is 400 or 406 for an unknown or missing version.
+func (r *Router) requestVersion(t *table, req *http.Request) (string, int) {
+	vc := r.cfg.Versioning
+
+	if vc.Strategy&VersionHeader != 0 {
+		if v := req.Header.Get(vc.Header); v != "" {
+			if !slices.Contains(t.versions, v) {
+				return v, http.StatusBadRequest
+			}
+			return v, 0
+		}
+	}
+
+	if vc.Strategy&VersionAccept != 0 {
+		if v := acceptVersion(req.Header.Get("Accept")); v != "" {
+			if !slices.Contains(t.versions, v) {
+				return v, http.StatusNotAcceptable
+			}
+			return v, 0
+		}
+	}
+
+	if vc.Default == "" {
+		return "", http.StatusBadRequest
+	}
+	return vc.Default, 0
+}
+
+// acceptVersion returns the version named by the first media range of
+// an Accept header that names one
+func acceptVersion(accept string) string {
+	for accept != "" {
+		var part string
+		part, accept, _ = strings.Cut(accept, ",")
+
+		mediatype, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		if v := params["version"]; v != "" {
+			return v
+		}
+
+		// application/vnd.acme.v2+json
+		_, subtype, _ := strings.Cut(mediatype, "/")
+		subtype, _, _ = strings.Cut(subtype, "+")
+		if !strings.HasPrefix(subtype, "vnd.") {
+			continue
+		}
+		if i := strings.LastIndex(subtype, ".v"); i >= 0 && i+2 < len(subtype) {
+			return subtype[i+2:]
+		}
+	}
+	return ""
+}
+
+// unknownVersion reports whether the path is under a version prefix, as
+// served by VersionPath, naming an undeclared version
+func (r *Router) unknownVersion(t *table, path string) bool {
+	if r.cfg.Versioning.Strategy&VersionPath == 0 {
+		return false
+	}
+	for _, prefix := range t.vprefixes {
+		if !matchPrefix(prefix, path, r.cfg.CaseInsensitive) {
+			continue
+		}
+		rest := trimSegments(path, strings.Count(strings.TrimSuffix(prefix, "/"), "/"))
+		if rest == "/" {
+			continue
+		}
+		seg, _ := nextSegment(rest)
+		if len(seg) > 1 && seg[0] == 'v' && seg[1] >= '0' && seg[1] <= '9' && !slices.Contains(t.versions, seg[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionError writes the response for an unknown or missing version
+func versionError(c *Context, status int) error {
+	return c.JSON(status, M{"error": "unsupported API version"})
+}
@@ -0,0 +1,257 @@
+package mux
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func versionedRouter(config VersionConfig) *Router {
+	r := New(Config{Versioning: config})
+	r.Version("1", func(g *Group) {
+		g.GET("/users", func(c *Context) error { return c.String(200, "v1 "+c.Version()) })
+	})
+	r.Version("2", func(g *Group) {
+		g.GET("/users", func(c *Context) error { return c.String(200, "v2 "+c.Version()) })
+		g.GET("/teams", func(c *Context) error { return c.String(200, "teams") })
+	})
+	return r
+}
+
+// -----------------------------------------------------------------------------
+// Versioning
+// -----------------------------------------------------------------------------
+
+func TestVersionPath(t *testing.T) {
+	r := versionedRouter(VersionConfig{})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/v1/users", 200, "v1 1"},
+		{"/v2/users", 200, "v2 2"},
+		{"/v1/teams", 404, ""},
+		{"/v9/users", 400, ""},
+		{"/users", 404, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, rec.Code)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestVersionHeader(t *testing.T) {
+	r := versionedRouter(VersionConfig{Strategy: VersionHeader, Default: "1"})
+
+	tests := []struct {
+		version string
+		code    int
+		body    string
+	}{
+		{"", 200, "v1 1"},
+		{"1", 200, "v1 1"},
+		{"2", 200, "v2 2"},
+		{"3", 400, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users", nil)
+		if tt.version != "" {
+			req.Header.Set("API-Version", tt.version)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("version %q: expected %d, got %d", tt.version, tt.code, rec.Code)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("version %q: expected %q, got %q", tt.version, tt.body, rec.Body.String())
+		}
+		if rec.Header().Get("Vary") != "API-Version" {
+			t.Errorf("version %q: expected Vary API-Version, got %q", tt.version, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestVersionAccept(t *testing.T) {
+	r := versionedRouter(VersionConfig{Strategy: VersionAccept})
+
+	tests := []struct {
+		accept string
+		code   int
+		body   string
+	}{
+		{"application/vnd.acme.v2+json", 200, "v2 2"},
+		{"text/html, application/vnd.acme.v1+json", 200, "v1 1"},
+		{"application/json; version=2", 200, "v2 2"},
+		{"application/vnd.acme.v7+json", 406, ""},
+		{"application/json", 400, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.accept, tt.code, rec.Code)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %q", tt.accept, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestVersionCombinedStrategies(t *testing.T) {
+	r := versionedRouter(VersionConfig{Strategy: VersionPath | VersionAccept, Default: "2"})
+
+	req := httptest.NewRequest("GET", "/v1/users", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Body.String() != "v1 1" {
+		t.Errorf("expected path version, got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept", "application/vnd.acme.v1+json")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Body.String() != "v1 1" {
+		t.Errorf("expected Accept version, got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/users", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Body.String() != "v2 2" {
+		t.Errorf("expected default version, got %q", rec.Body.String())
+	}
+}
+
+func TestVersionMissingInVersion(t *testing.T) {
+	r := versionedRouter(VersionConfig{Strategy: VersionHeader})
+
+	req := httptest.NewRequest("GET", "/teams", nil)
+	req.Header.Set("API-Version", "1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Errorf("expected 404 for route missing in version, got %d", rec.Code)
+	}
+}
+
+func TestVersionGroupPrefixAndMiddleware(t *testing.T) {
+	r := New(Config{Versioning: VersionConfig{Strategy: VersionPath | VersionHeader}})
+	api := r.Group("/api")
+	api.Use(func(next Handler) Handler {
+		return func(c *Context) error {
+			c.SetHeader("X-Api", "1")
+			return next(c)
+		}
+	})
+	api.OnErr(func(c *Context, err error) { _ = c.String(418, err.Error()) })
+	api.Version("2", func(g *Group) {
+		g.GET("/items/{id}", func(c *Context) error { return errors.New("boom") })
+	})
+
+	for _, path := range []string{"/api/v2/items/1", "/api/items/1"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("API-Version", "2")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != 418 || rec.Header().Get("X-Api") != "1" {
+			t.Errorf("%s: expected group middleware and error handler, got %d %q", path, rec.Code, rec.Header().Get("X-Api"))
+		}
+	}
+}
+
+func TestVersionUnversionedFallback(t *testing.T) {
+	configs := map[string]Config{
+		"header":  {Versioning: VersionConfig{Strategy: VersionHeader}},
+		"default": {Versioning: VersionConfig{Strategy: VersionPath, Default: "2"}},
+		"tree":    {Versioning: VersionConfig{Strategy: VersionHeader}, Matcher: NewTree},
+	}
+	for name, cfg := range configs {
+		r := New(cfg)
+		r.GET("/users", func(c *Context) error { return c.String(200, "unversioned") })
+		r.Version("2", func(g *Group) {
+			g.GET("/users", func(c *Context) error { return c.String(200, "v2") })
+		})
+		r.Version("3", func(g *Group) {
+			g.GET("/teams", func(c *Context) error { return c.String(200, "teams") })
+		})
+
+		tests := map[string]string{"": "unversioned", "2": "v2", "3": "unversioned"}
+		if cfg.Versioning.Default != "" {
+			tests[""] = "v2"
+		}
+		for version, want := range tests {
+			if cfg.Versioning.Strategy&VersionHeader == 0 && version != "" {
+				continue
+			}
+			req := httptest.NewRequest("GET", "/users", nil)
+			if version != "" {
+				req.Header.Set("API-Version", version)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Body.String() != want {
+				t.Errorf("%s: version %q: expected %q, got %d %q", name, version, want, rec.Code, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestVersionRoutes(t *testing.T) {
+	r := versionedRouter(VersionConfig{})
+
+	routes := r.Routes()
+	if len(routes) != 3 || routes[0].Version != "1" || routes[1].Version != "2" {
+		t.Errorf("expected versions in route table, got %+v", routes)
+	}
+	if routes[0].Pattern != "/users" {
+		t.Errorf("expected unprefixed pattern, got %s", routes[0].Pattern)
+	}
+}
+
+func TestVersionDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for duplicate versioned route")
+		}
+	}()
+	r := New(Config{Versioning: VersionConfig{Strategy: VersionHeader}})
+	r.Version("1", func(g *Group) {
+		g.GET("/users", listUsers)
+		g.GET("/users", listUsers)
+	})
+}
+
+func TestAcceptVersion(t *testing.T) {
+	tests := map[string]string{
+		"application/vnd.acme.v2+json":        "2",
+		"application/vnd.acme.v2.1+json":      "2.1",
+		"application/json;version=5":          "5",
+		"text/html, application/json":         "",
+		"application/vnd.acme+json":           "",
+		"application/vnd.acme.v2+json;q=0.5,": "2",
+	}
+	for accept, want := range tests {
+		if got := acceptVersion(accept); got != want {
+			t.Errorf("%s: expected %q, got %q", accept, want, got)
+		}
+	}
+}